@@ -0,0 +1,173 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+type CaptionTracks []CaptionTrack
+
+type CaptionTrack struct {
+	BaseURL        string
+	Name           string
+	LanguageCode   string
+	Kind           string
+	IsTranslatable bool
+
+	// IsAutoGenerated is set for tracks created by speech recognition (kind "asr")
+	IsAutoGenerated bool
+}
+
+func (track captionTrackData) CaptionTrack() CaptionTrack {
+	return CaptionTrack{
+		BaseURL:         track.BaseURL,
+		Name:            track.Name.String(),
+		LanguageCode:    track.LanguageCode,
+		Kind:            track.Kind,
+		IsTranslatable:  track.IsTranslatable,
+		IsAutoGenerated: track.Kind == "asr" || strings.HasPrefix(track.VssID, "a."),
+	}
+}
+
+// Language returns a new CaptionTracks filtered by language code.
+// A language without region like "en" also matches regional codes like "en-GB".
+func (tracks CaptionTracks) Language(lang string) (result CaptionTracks) {
+	for _, track := range tracks {
+		if track.LanguageCode == lang || strings.HasPrefix(track.LanguageCode, lang+"-") {
+			result = append(result, track)
+		}
+	}
+	return result
+}
+
+// HumanAuthored returns a new CaptionTracks without auto-generated tracks
+func (tracks CaptionTracks) HumanAuthored() (result CaptionTracks) {
+	for _, track := range tracks {
+		if !track.IsAutoGenerated {
+			result = append(result, track)
+		}
+	}
+	return result
+}
+
+// FindByLanguage returns the best track for the language.
+// Human authored tracks are preferred over auto-generated ones.
+func (tracks CaptionTracks) FindByLanguage(lang string) *CaptionTrack {
+	var auto *CaptionTrack
+	for i := range tracks {
+		track := &tracks[i]
+		if track.LanguageCode != lang && !strings.HasPrefix(track.LanguageCode, lang+"-") {
+			continue
+		}
+		if !track.IsAutoGenerated {
+			return track
+		}
+		if auto == nil {
+			auto = track
+		}
+	}
+	return auto
+}
+
+// CaptionFormat is the format captions are requested in
+type CaptionFormat string
+
+const (
+	// CaptionFormatXML is the default timedtext XML with segment-level timing
+	CaptionFormatXML CaptionFormat = ""
+	// CaptionFormatJSON3 provides word-level timing, if available for the track
+	CaptionFormatJSON3 CaptionFormat = "json3"
+	// CaptionFormatSRT is rendered from the cues of the XML format
+	CaptionFormatSRT CaptionFormat = "srt"
+	// CaptionFormatVTT is rendered from the cues of the XML format
+	CaptionFormatVTT CaptionFormat = "vtt"
+)
+
+// requestFormat returns the format to request from YouTube
+func (format CaptionFormat) requestFormat() CaptionFormat {
+	switch format {
+	case CaptionFormatSRT, CaptionFormatVTT:
+		return CaptionFormatXML
+	default:
+		return format
+	}
+}
+
+// GetCaptions fetches the captions of a track in the given format and returns them as cues
+func (c *Client) GetCaptions(track *CaptionTrack, format CaptionFormat) ([]Cue, error) {
+	return c.GetCaptionsContext(context.Background(), track, format)
+}
+
+// GetCaptionsContext fetches the captions of a track in the given format with a context and returns them as cues
+func (c *Client) GetCaptionsContext(ctx context.Context, track *CaptionTrack, format CaptionFormat) ([]Cue, error) {
+	url, err := captionsURL(track, format)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.httpGetBodyBytes(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCues(body)
+}
+
+// DownloadCaptions writes the captions of a track in the given format to w
+func (c *Client) DownloadCaptions(track *CaptionTrack, w io.Writer, format CaptionFormat) error {
+	return c.DownloadCaptionsContext(context.Background(), track, w, format)
+}
+
+// DownloadCaptionsContext writes the captions of a track in the given format to w with a context.
+// The XML and json3 formats are written as received, SRT and VTT are rendered from the cues.
+func (c *Client) DownloadCaptionsContext(ctx context.Context, track *CaptionTrack, w io.Writer, format CaptionFormat) error {
+	switch format {
+	case CaptionFormatSRT, CaptionFormatVTT:
+		cues, err := c.GetCaptionsContext(ctx, track, format)
+		if err != nil {
+			return err
+		}
+		if format == CaptionFormatSRT {
+			return WriteSRT(w, cues)
+		}
+		return WriteVTT(w, cues)
+	}
+
+	url, err := captionsURL(track, format)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpGet(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func captionsURL(track *CaptionTrack, format CaptionFormat) (string, error) {
+	if track.BaseURL == "" {
+		return "", errors.New("caption track has no URL")
+	}
+
+	uri, err := url.Parse(track.BaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := uri.Query()
+	if format = format.requestFormat(); format == CaptionFormatXML {
+		query.Del("fmt")
+	} else {
+		query.Set("fmt", string(format))
+	}
+	uri.RawQuery = query.Encode()
+
+	return uri.String(), nil
+}
@@ -0,0 +1,44 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideo_CaptionTracks(t *testing.T) {
+	require := require.New(t)
+
+	body := []byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]},
+		"captions": {"playerCaptionsTracklistRenderer": {"captionTracks": [
+			{"baseUrl": "https://www.youtube.com/api/timedtext?lang=en&kind=asr", "name": {"simpleText": "English (auto-generated)"}, "vssId": "a.en", "languageCode": "en", "kind": "asr", "isTranslatable": true},
+			{"baseUrl": "https://www.youtube.com/api/timedtext?lang=en", "name": {"runs": [{"text": "English"}]}, "vssId": ".en", "languageCode": "en", "isTranslatable": true}
+		]}}
+	}`)
+
+	v := &Video{}
+	require.NoError(v.parseVideoInfo(body))
+	require.Len(v.CaptionTracks, 2)
+
+	assert.True(t, v.CaptionTracks[0].IsAutoGenerated)
+	assert.Equal(t, "English (auto-generated)", v.CaptionTracks[0].Name)
+	assert.False(t, v.CaptionTracks[1].IsAutoGenerated)
+	assert.Equal(t, "English", v.CaptionTracks[1].Name)
+}
+
+func TestCaptionTracks_FindByLanguage(t *testing.T) {
+	tracks := CaptionTracks{
+		{LanguageCode: "en", IsAutoGenerated: true},
+		{LanguageCode: "de"},
+		{LanguageCode: "en-GB"},
+		{LanguageCode: "fr", IsAutoGenerated: true},
+	}
+
+	assert.Same(t, &tracks[2], tracks.FindByLanguage("en"))
+	assert.Same(t, &tracks[1], tracks.FindByLanguage("de"))
+	assert.Same(t, &tracks[3], tracks.FindByLanguage("fr"))
+	assert.Nil(t, tracks.FindByLanguage("es"))
+}
@@ -0,0 +1,148 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	sjson "github.com/bitly/go-simplejson"
+)
+
+var (
+	channelIDRegex    = regexp.MustCompile("^UC[A-Za-z0-9_-]{22}$")
+	channelInURLRegex = regexp.MustCompile("/channel/(UC[A-Za-z0-9_-]{22})(?:[/?&#].*)?$")
+)
+
+// channelAboutParams selects the about tab of a channel in browse requests
+const channelAboutParams = "EgVhYm91dA=="
+
+// ChannelAbout contains the statistics of the about page of a channel
+type ChannelAbout struct {
+	ID          string
+	Title       string
+	Description string
+	JoinDate    time.Time
+	ViewCount   int64
+	Country     string
+	Links       []ChannelLink
+}
+
+type ChannelLink struct {
+	Title string
+	URL   string
+}
+
+func extractChannelID(url string) (string, error) {
+	if channelIDRegex.MatchString(url) {
+		return url, nil
+	}
+
+	matches := channelInURLRegex.FindStringSubmatch(url)
+
+	if matches != nil {
+		return matches[1], nil
+	}
+
+	return "", ErrInvalidChannel
+}
+
+// uploadsPlaylistID returns the ID of the playlist containing all uploads of the channel
+func uploadsPlaylistID(channelID string) string {
+	return "UU" + strings.TrimPrefix(channelID, "UC")
+}
+
+// structs for channel about extraction
+
+// Renderer: contents.twoColumnBrowseResultsRenderer.tabs[].tabRenderer.content.sectionListRenderer.contents[0].itemSectionRenderer.contents[0].channelAboutFullMetadataRenderer
+// JoinDate: .joinedDateText.runs[1].text
+// ViewCount: .viewCountText.simpleText
+// Country: .country.simpleText
+// Links: .primaryLinks[].title.simpleText / .primaryLinks[].navigationEndpoint.urlEndpoint.url
+func (about *ChannelAbout) parseChannelAbout(body []byte) (err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	renderer := j.GetPath("alerts").GetIndex(0).GetPath("alertRenderer")
+	if renderer != nil && renderer.GetPath("type").MustString() == "ERROR" {
+		message := renderer.GetPath("text", "runs").GetIndex(0).GetPath("text").MustString()
+
+		return ErrChannelStatus{Reason: message}
+	}
+
+	tabs := j.GetPath("contents", "twoColumnBrowseResultsRenderer", "tabs")
+	for i := range tabs.MustArray() {
+		metadata, ok := tabs.GetIndex(i).GetPath("tabRenderer", "content", "sectionListRenderer", "contents").GetIndex(0).
+			GetPath("itemSectionRenderer", "contents").GetIndex(0).
+			CheckGet("channelAboutFullMetadataRenderer")
+		if !ok {
+			continue
+		}
+
+		data, err := metadata.MarshalJSON()
+		if err != nil {
+			return err
+		}
+
+		var extractor channelAboutJSONExtractor
+		if err = json.Unmarshal(data, &extractor); err != nil {
+			return err
+		}
+
+		extractor.apply(about)
+		return nil
+	}
+
+	return errors.New("no channel about metadata found in the server's answer")
+}
+
+type channelAboutJSONExtractor struct {
+	Title        simpleText `json:"title"`
+	Description  simpleText `json:"description"`
+	JoinedDate   simpleText `json:"joinedDateText"`
+	ViewCount    simpleText `json:"viewCountText"`
+	Country      simpleText `json:"country"`
+	PrimaryLinks []struct {
+		Title              simpleText `json:"title"`
+		NavigationEndpoint struct {
+			URLEndpoint struct {
+				URL string `json:"url"`
+			} `json:"urlEndpoint"`
+		} `json:"navigationEndpoint"`
+	} `json:"primaryLinks"`
+}
+
+func (cje channelAboutJSONExtractor) apply(about *ChannelAbout) {
+	about.Title = cje.Title.String()
+	about.Description = cje.Description.String()
+	about.Country = cje.Country.String()
+
+	joined := strings.TrimSpace(strings.TrimPrefix(cje.JoinedDate.String(), "Joined"))
+	about.JoinDate, _ = time.Parse("Jan 2, 2006", joined)
+
+	about.ViewCount, _ = strconv.ParseInt(strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, cje.ViewCount.String()), 10, 64)
+
+	for _, link := range cje.PrimaryLinks {
+		about.Links = append(about.Links, ChannelLink{
+			Title: link.Title.String(),
+			URL:   link.NavigationEndpoint.URLEndpoint.URL,
+		})
+	}
+}
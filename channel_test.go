@@ -0,0 +1,80 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYoutube_extractChannelID(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedID    string
+		expectedError error
+	}{
+		{"id", "UCVHFbqXqoYvEWM1Ddxl0QDg", "UCVHFbqXqoYvEWM1Ddxl0QDg", nil},
+		{"url", "https://www.youtube.com/channel/UCVHFbqXqoYvEWM1Ddxl0QDg", "UCVHFbqXqoYvEWM1Ddxl0QDg", nil},
+		{"url with tab", "https://www.youtube.com/channel/UCVHFbqXqoYvEWM1Ddxl0QDg/videos", "UCVHFbqXqoYvEWM1Ddxl0QDg", nil},
+		{"too short", "UCVHFbqXqoYvEWM1Ddxl", "", ErrInvalidChannel},
+		{"empty", "", "", ErrInvalidChannel},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			id, err := extractChannelID(v.url)
+
+			assert.Equal(t, v.expectedError, err)
+			assert.Equal(t, v.expectedID, id)
+		})
+	}
+}
+
+func TestYoutube_uploadsPlaylistID(t *testing.T) {
+	assert.Equal(t, "UUVHFbqXqoYvEWM1Ddxl0QDg", uploadsPlaylistID("UCVHFbqXqoYvEWM1Ddxl0QDg"))
+}
+
+func TestChannelAbout_parseChannelAbout(t *testing.T) {
+	body := []byte(`{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [
+		{"tabRenderer": {"title": "Home"}},
+		{"tabRenderer": {"title": "About", "content": {"sectionListRenderer": {"contents": [{"itemSectionRenderer": {"contents": [{
+			"channelAboutFullMetadataRenderer": {
+				"title": {"simpleText": "Google Developers"},
+				"description": {"simpleText": "Videos for developers"},
+				"joinedDateText": {"runs": [{"text": "Joined "}, {"text": "Aug 23, 2007"}]},
+				"viewCountText": {"simpleText": "251,680,890 views"},
+				"primaryLinks": [{"title": {"simpleText": "Blog"}, "navigationEndpoint": {"urlEndpoint": {"url": "https://developers.googleblog.com/"}}}]
+			}
+		}]}}]}}}}
+	]}}}`)
+
+	about := &ChannelAbout{ID: "UC_x5XG1OV2P6uZZ5FSM9Ttw"}
+	require.NoError(t, about.parseChannelAbout(body))
+
+	assert.Equal(t, "Google Developers", about.Title)
+	assert.Equal(t, "Videos for developers", about.Description)
+	assert.Equal(t, "2007-08-23", about.JoinDate.Format(dateFormat))
+	assert.EqualValues(t, 251680890, about.ViewCount)
+	assert.Empty(t, about.Country)
+	assert.Equal(t, []ChannelLink{{Title: "Blog", URL: "https://developers.googleblog.com/"}}, about.Links)
+
+	assert.EqualError(t, (&ChannelAbout{}).parseChannelAbout([]byte(`{}`)), "no channel about metadata found in the server's answer")
+}
+
+func TestClient_GetChannelAboutContext_English(t *testing.T) {
+	var request innertubeRequest
+	client := &Client{Language: "de", HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}
+	})}}
+
+	// the empty answer has no metadata, only the request matters
+	_, _ = client.GetChannelAboutContext(context.Background(), "UC_x5XG1OV2P6uZZ5FSM9Ttw")
+	assert.Equal(t, "en", request.Context.Client.HL, "the join date is only parsed in English")
+}
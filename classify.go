@@ -0,0 +1,113 @@
+package youtube
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kind is the type of content a YouTube URL refers to
+type Kind string
+
+const (
+	KindVideo    Kind = "video"
+	KindPlaylist Kind = "playlist"
+	KindChannel  Kind = "channel"
+	KindHandle   Kind = "handle"
+	KindClip     Kind = "clip"
+
+	// KindLive is a live stream or its recording, given by a /live/ URL. The ID is the video ID,
+	// so it can be fetched like a video.
+	KindLive Kind = "live"
+)
+
+var (
+	handleRegex = regexp.MustCompile(`^@[A-Za-z0-9_.-]{3,30}$`)
+	clipRegex   = regexp.MustCompile(`^/clip/([A-Za-z0-9_-]+)/?$`)
+)
+
+// ClassifyURL determines what the given URL refers to and extracts its identifier, so callers can
+// route it to GetVideo, GetPlaylist, GetChannelAbout etc. Bare IDs and handles are accepted as well.
+// Handles are returned including their leading @. A watch URL with a playlist is classified as video,
+// a /live/ URL as KindLive with the video ID.
+func ClassifyURL(rawURL string) (Kind, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+
+	if !strings.Contains(rawURL, "/") {
+		return classifyID(rawURL)
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", ErrUnsupportedURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(uri.Hostname()), "www.")
+	switch {
+	case host == "youtu.be":
+		return classifyVideo(rawURL)
+	case host != "youtube.com" && !strings.HasSuffix(host, ".youtube.com") && host != "youtube-nocookie.com":
+		return "", "", ErrUnsupportedURL
+	}
+
+	segments := strings.Split(strings.Trim(uri.Path, "/"), "/")
+	switch {
+	case uri.Query().Get("v") != "":
+		return classifyVideo(rawURL)
+	case clipRegex.MatchString(uri.Path):
+		return KindClip, clipRegex.FindStringSubmatch(uri.Path)[1], nil
+	case handleRegex.MatchString(segments[0]):
+		return KindHandle, segments[0], nil
+	case segments[0] == "channel":
+		id, err := extractChannelID(rawURL)
+		return KindChannel, id, err
+	case segments[0] == "playlist" || segments[0] == "browse":
+		id, err := extractPlaylistID(rawURL)
+		return KindPlaylist, id, err
+	case len(segments) == 2 && segments[0] == "live":
+		_, id, err := classifyVideo(segments[1])
+		if err != nil {
+			return "", "", err
+		}
+		return KindLive, id, nil
+	case len(segments) == 2 && isVideoPath(segments[0]):
+		return classifyVideo(segments[1])
+	}
+
+	return "", "", ErrUnsupportedURL
+}
+
+// classifyID classifies an identifier which isn't an URL
+func classifyID(id string) (Kind, string, error) {
+	switch {
+	case handleRegex.MatchString(id):
+		return KindHandle, id, nil
+	case channelIDRegex.MatchString(id):
+		return KindChannel, id, nil
+	case len(id) == 11:
+		return classifyVideo(id)
+	case playlistIDRegex.MatchString(id):
+		return KindPlaylist, id, nil
+	}
+
+	return "", "", ErrUnsupportedURL
+}
+
+func classifyVideo(videoURL string) (Kind, string, error) {
+	id, err := ExtractVideoID(videoURL)
+	if err != nil {
+		return "", "", err
+	}
+	return KindVideo, id, nil
+}
+
+func isVideoPath(segment string) bool {
+	switch segment {
+	case "embed", "shorts", "v", "e":
+		return true
+	}
+	return false
+}
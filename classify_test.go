@@ -0,0 +1,51 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		kind Kind
+		id   string
+		err  error
+	}{
+		{"https://www.youtube.com/watch?v=BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"https://www.youtube.com/watch?v=BaW_jenozKc&list=PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", KindVideo, "BaW_jenozKc", nil},
+		{"youtube.com/watch?v=BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"https://youtu.be/BaW_jenozKc?t=10", KindVideo, "BaW_jenozKc", nil},
+		{"https://music.youtube.com/watch?v=BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"https://www.youtube.com/shorts/BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"https://www.youtube.com/live/BaW_jenozKc", KindLive, "BaW_jenozKc", nil},
+		{"https://www.youtube.com/live/BaW_jenozKc?si=abc", KindLive, "BaW_jenozKc", nil},
+		{"https://www.youtube-nocookie.com/embed/BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"BaW_jenozKc", KindVideo, "BaW_jenozKc", nil},
+		{"https://www.youtube.com/playlist?list=PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", KindPlaylist, "PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", nil},
+		{"https://music.youtube.com/browse/MPSPPLqAfPOrmacr963ATEroh67fbvjmTzTEx5", KindPlaylist, "PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", nil},
+		{"PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", KindPlaylist, "PLqAfPOrmacr963ATEroh67fbvjmTzTEx5", nil},
+		{"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw/videos", KindChannel, "UCuAXFkgsw1L7xaCfnd5JJOw", nil},
+		{"UCuAXFkgsw1L7xaCfnd5JJOw", KindChannel, "UCuAXFkgsw1L7xaCfnd5JJOw", nil},
+		{"https://www.youtube.com/@YouTube/videos", KindHandle, "@YouTube", nil},
+		{"@YouTube", KindHandle, "@YouTube", nil},
+		{"https://www.youtube.com/clip/UgkxU2HSeGL_NvmDJ-nQJrlLwllwMDBdGZFs", KindClip, "UgkxU2HSeGL_NvmDJ-nQJrlLwllwMDBdGZFs", nil},
+		{"https://vimeo.com/watch?v=BaW_jenozKc", "", "", ErrUnsupportedURL},
+		{"https://www.youtube.com/feed/trending", "", "", ErrUnsupportedURL},
+		{"short", "", "", ErrUnsupportedURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			kind, id, err := ClassifyURL(tt.url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
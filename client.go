@@ -2,12 +2,18 @@ package youtube
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
 )
 
 // Client offers methods to download video metadata and video streams.
@@ -19,8 +25,75 @@ type Client struct {
 	// If not set, http.DefaultClient will be used
 	HTTPClient *http.Client
 
+	// DialContext replaces the dialer of the HTTP transport, e.g. to pin connections to a specific
+	// edge server for latency testing. It affects the connections to the API as well as to the CDN
+	// serving the streams, which are different hosts, so it has to handle both. It's applied to a
+	// copy of the transport of HTTPClient, which has to be an *http.Transport or nil. The copy is
+	// made on the first request, later changes of DialContext or HTTPClient aren't picked up.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// AutoRefreshOnStreamError enables fetching the video again when a stream responds with
+	// 403 Forbidden, e.g. because the URL expired or is bound to another IP. The stream continues
+	// with the fresh URL of the same itag from the current byte offset. Only formats with a known
+	// ContentLength are supported, as they are downloaded in chunks.
+	AutoRefreshOnStreamError bool
+
+	// StreamRetries is the number of times a stream is requested again from the current
+	// byte offset when its connection drops before ContentLength is reached. Zero disables retries.
+	StreamRetries int
+
+	// ChunkRetries is the number of times a single chunk of a format with a known ContentLength
+	// is requested again after a network error or a server error, with an increasing backoff.
+	// If the retries are exhausted the stream fails with an *ErrChunkFailed.
+	ChunkRetries int
+
+	// RequestRetries is the number of times a request to the innertube API is sent again after a
+	// network error or a server error, with an increasing backoff. Zero disables retries.
+	RequestRetries int
+
+	// TotalRetryBudget caps the request retries, stream retries, chunk retries and stream URL
+	// refreshes together within a single call like GetVideo, GetStream, GetPlaylist or
+	// GetChannelUploads, so they can't multiply. Once the budget is exhausted, errors fail fast
+	// wrapped in ErrRetryBudgetExhausted. Zero doesn't limit retries.
+	// Use WithRetryBudget to share a budget across several calls.
+	TotalRetryBudget int
+
+	// VisitorData identifies the visitor to the innertube API, and POToken is a proof of origin
+	// token generated for it. Both are needed to pass the "Sign in to confirm you're not a bot"
+	// check, returned as ErrBotCheck, without signing in. The PO token is also added to the
+	// stream URLs. They have to be obtained from a browser session, e.g. by a PO token generator.
+	VisitorData string
+	POToken     string
+
+	// Language is the hl parameter of innertube requests, which selects the language of the
+	// metadata, e.g. "de". AcceptLanguage is the Accept-Language header of all HTTP requests,
+	// e.g. "de-DE,de;q=0.9". Language defaults to "en" and AcceptLanguage to Language.
+	Language       string
+	AcceptLanguage string
+
+	// Region is the gl parameter of innertube requests, the country of the content like the
+	// trending videos, e.g. "DE". It defaults to "US".
+	Region string
+
+	// ValidateStreamURLs probes the stream URL of every format with a one byte range request when
+	// fetching a video. Formats which aren't reachable are dropped. This adds the latency of a request,
+	// but ensures the URLs are usable, e.g. before queueing downloads.
+	ValidateStreamURLs bool
+
+	// TempDir is the directory of the temporary files of downloads and muxing in the downloader
+	// package, e.g. to keep them off a small /tmp. If empty, os.TempDir is used.
+	TempDir string
+
+	// DislikeProvider is an optional external source for the number of dislikes, which YouTube
+	// no longer publishes. If set, it's called by GetVideo to fill in Video.Dislikes.
+	DislikeProvider DislikeProvider
+
 	// playerCache caches the JavaScript code of a player response
 	playerCache playerCache
+
+	// dialClient holds the *http.Client using DialContext, created on first use. It's an atomic.Value
+	// rather than a mutex guarded field, so the Client stays copyable.
+	dialClient atomic.Value
 }
 
 // GetVideo fetches video metadata
@@ -28,16 +101,113 @@ func (c *Client) GetVideo(url string) (*Video, error) {
 	return c.GetVideoContext(context.Background(), url)
 }
 
-// GetVideoContext fetches video metadata with a context
+// GetVideoContext fetches video metadata with a context.
+//
+// All formats are read from the streamingData of the innertube player response, so
+// no watch page is needed. The player JavaScript is fetched once (and cached) for the
+// signature timestamp. The watch page is only requested as fallback for videos which
+// must not be played in embeds. Data which is not part of the player response at all,
+// like the description links or chapters, is fetched separately by GetVideoDetails.
 func (c *Client) GetVideoContext(ctx context.Context, url string) (*Video, error) {
 	id, err := ExtractVideoID(url)
 	if err != nil {
 		return nil, fmt.Errorf("extractVideoID failed: %w", err)
 	}
-	return c.videoFromID(ctx, id)
+
+	ctx = WithRetryBudget(ctx, c.TotalRetryBudget)
+
+	v, err := c.videoFromID(ctx, id)
+	if v != nil {
+		v.RequestedStart = extractStartTime(url)
+	}
+	return v, err
 }
 
 func (c *Client) videoFromID(ctx context.Context, id string) (*Video, error) {
+	v, err := c.videoFromPlayer(ctx, id)
+	if err != nil {
+		return v, err
+	}
+
+	if c.ValidateStreamURLs {
+		if err = c.validateStreamURLs(ctx, v); err != nil {
+			return v, err
+		}
+	}
+
+	c.extendFromDislikeProvider(ctx, v)
+	return v, nil
+}
+
+// DislikeProvider returns the number of dislikes of a video, e.g. from the Return YouTube Dislike API
+type DislikeProvider interface {
+	Dislikes(ctx context.Context, videoID string) (int64, error)
+}
+
+// extendFromDislikeProvider adds the dislikes of the configured provider.
+// Errors are only logged, as the video is usable without them.
+func (c *Client) extendFromDislikeProvider(ctx context.Context, v *Video) {
+	if c.DislikeProvider == nil {
+		return
+	}
+
+	dislikes, err := c.DislikeProvider.Dislikes(ctx, v.ID)
+	if err != nil {
+		c.logf("unable to get dislikes of video %s: %v", v.ID, err)
+		return
+	}
+	v.Dislikes = dislikes
+}
+
+// GetVideoDetails adds the metadata which isn't part of the player response
+func (c *Client) GetVideoDetails(v *Video) error {
+	return c.GetVideoDetailsContext(context.Background(), v)
+}
+
+// GetVideoDetailsContext adds the metadata which isn't part of the player response with a context:
+// the description links, key moments, product tags, derived clips, chapters and whether comments
+// are disabled. It costs another request to the watch next endpoint, plus one per further page of
+// chapters. The metadata parsed before an error is kept.
+func (c *Client) GetVideoDetailsContext(ctx context.Context, v *Video) error {
+	data := innertubeRequest{
+		VideoID: v.ID,
+		Context: c.prepareInnertubeContext(webClient),
+	}
+
+	body, err := c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/next?key="+webClient.key, data)
+	if err != nil {
+		return err
+	}
+	if err = v.parseWatchNext(body); err != nil {
+		return err
+	}
+
+	for v.chaptersContinuation != "" {
+		continuation := v.chaptersContinuation
+		data := innertubeRequest{
+			Continuation: continuation,
+			Context:      c.prepareInnertubeContext(webClient),
+		}
+
+		body, err = c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/next?key="+webClient.key, data)
+		if err == nil {
+			v.chaptersContinuation, err = v.parseChaptersContinuation(body)
+		}
+		if err != nil {
+			v.chaptersContinuation = ""
+			return fmt.Errorf("unable to get more chapters: %w", err)
+		}
+		if v.chaptersContinuation == continuation {
+			// the same token again would never end
+			v.chaptersContinuation = ""
+		}
+	}
+
+	return nil
+}
+
+// videoFromPlayer fetches the video metadata and formats from the player response
+func (c *Client) videoFromPlayer(ctx context.Context, id string) (*Video, error) {
 	body, err := c.videoDataByInnertube(ctx, id, webClient)
 	if err != nil {
 		return nil, err
@@ -53,7 +223,16 @@ func (c *Client) videoFromID(ctx context.Context, id string) (*Video, error) {
 		return v, nil
 	}
 
-	// If the uploader has disabled embedding the video on other sites, parse video page
+	// tell which credentials could help to pass the bot check
+	var botErr *ErrBotCheck
+	if errors.As(err, &botErr) {
+		botErr.MissingVisitorData = c.VisitorData == ""
+		botErr.MissingPOToken = c.POToken == ""
+		return v, botErr
+	}
+
+	// If the uploader has disabled embedding the video on other sites, parse video page.
+	// This is the only case requiring the watch page to get the formats.
 	if err == ErrNotPlayableInEmbed {
 		// additional parameters are required to access clips with sensitiv content
 		html, err := c.httpGetBodyBytes(ctx, "https://www.youtube.com/watch?v="+id+"&bpctr=9999999999&has_verified=1")
@@ -91,9 +270,16 @@ func (c *Client) videoFromID(ctx context.Context, id string) (*Video, error) {
 type innertubeRequest struct {
 	VideoID         string            `json:"videoId,omitempty"`
 	BrowseID        string            `json:"browseId,omitempty"`
+	Params          string            `json:"params,omitempty"`
 	Continuation    string            `json:"continuation,omitempty"`
 	Context         inntertubeContext `json:"context"`
 	PlaybackContext playbackContext   `json:"playbackContext,omitempty"`
+
+	ServiceIntegrityDimensions *serviceIntegrityDimensions `json:"serviceIntegrityDimensions,omitempty"`
+}
+
+type serviceIntegrityDimensions struct {
+	PoToken string `json:"poToken"`
 }
 
 type playbackContext struct {
@@ -113,6 +299,7 @@ type innertubeClient struct {
 	GL            string `json:"gl"`
 	ClientName    string `json:"clientName"`
 	ClientVersion string `json:"clientVersion"`
+	VisitorData   string `json:"visitorData,omitempty"`
 }
 
 // client info for the innertube API
@@ -149,7 +336,7 @@ func (c *Client) videoDataByInnertube(ctx context.Context, id string, clientInfo
 		return nil, err
 	}
 
-	context := prepareInnertubeContext(clientInfo)
+	context := c.prepareInnertubeContext(clientInfo)
 
 	data := innertubeRequest{
 		VideoID: id,
@@ -161,22 +348,48 @@ func (c *Client) videoDataByInnertube(ctx context.Context, id string, clientInfo
 		},
 	}
 
+	if c.POToken != "" {
+		data.ServiceIntegrityDimensions = &serviceIntegrityDimensions{PoToken: c.POToken}
+	}
+
 	return c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/player?key="+clientInfo.key, data)
 }
 
-func prepareInnertubeContext(clientInfo clientInfo) inntertubeContext {
+func (c *Client) prepareInnertubeContext(clientInfo clientInfo) inntertubeContext {
 	return inntertubeContext{
 		Client: innertubeClient{
-			HL:            "en",
-			GL:            "US",
+			HL:            c.language(),
+			GL:            c.region(),
 			ClientName:    clientInfo.name,
 			ClientVersion: clientInfo.version,
+			VisitorData:   c.VisitorData,
 		},
 	}
 }
 
-func prepareInnertubePlaylistData(ID string, continuation bool, clientInfo clientInfo) innertubeRequest {
-	context := prepareInnertubeContext(clientInfo)
+func (c *Client) language() string {
+	if c.Language != "" {
+		return c.Language
+	}
+	return "en"
+}
+
+func (c *Client) region() string {
+	if c.Region != "" {
+		return c.Region
+	}
+	return "US"
+}
+
+func (c *Client) acceptLanguage() string {
+	if c.AcceptLanguage != "" {
+		return c.AcceptLanguage
+	}
+	return c.language()
+}
+
+func (c *Client) prepareInnertubePlaylistData(ID string, continuation bool, clientInfo clientInfo) innertubeRequest {
+	context := c.prepareInnertubeContext(clientInfo)
 
 	if continuation {
 		return innertubeRequest{Context: context, Continuation: ID}
@@ -199,14 +412,71 @@ func (c *Client) GetPlaylistContext(ctx context.Context, url string) (*Playlist,
 		return nil, fmt.Errorf("extractPlaylistID failed: %w", err)
 	}
 
-	data := prepareInnertubePlaylistData(id, false, webClient)
+	// the pages of the playlist share the retry budget
+	ctx = WithRetryBudget(ctx, c.TotalRetryBudget)
+
+	data := c.prepareInnertubePlaylistData(id, false, webClient)
 	body, err := c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+webClient.key, data)
 	if err != nil {
 		return nil, err
 	}
 
 	p := &Playlist{ID: id}
-	return p, p.parsePlaylistInfo(ctx, c, body)
+	return p, p.parsePlaylistInfo(ctx, c, body, nil)
+}
+
+// GetChannelUploadsContext fetches the uploads playlist of a channel, newest videos first.
+// The stop function is called for every entry and paging ends as soon as it returns true,
+// so only the needed part of the uploads has to be fetched. The stopping entry is still
+// part of the returned playlist.
+func (c *Client) GetChannelUploadsContext(ctx context.Context, channelID string, stop func(*PlaylistEntry) bool) (*Playlist, error) {
+	id, err := extractChannelID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("extractChannelID failed: %w", err)
+	}
+
+	// the pages of the uploads share the retry budget
+	ctx = WithRetryBudget(ctx, c.TotalRetryBudget)
+	playlistID := uploadsPlaylistID(id)
+	data := c.prepareInnertubePlaylistData(playlistID, false, webClient)
+	body, err := c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+webClient.key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Playlist{ID: playlistID}
+	return p, p.parsePlaylistInfo(ctx, c, body, stop)
+}
+
+// GetChannelAbout fetches the about page of a channel
+func (c *Client) GetChannelAbout(channelID string) (*ChannelAbout, error) {
+	return c.GetChannelAboutContext(context.Background(), channelID)
+}
+
+// GetChannelAboutContext fetches the about page of a channel with a context.
+// Fields missing on the page are left empty. The page is requested in English regardless of
+// Client.Language, so e.g. the Country is an English name.
+func (c *Client) GetChannelAboutContext(ctx context.Context, channelID string) (*ChannelAbout, error) {
+	id, err := extractChannelID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("extractChannelID failed: %w", err)
+	}
+
+	data := innertubeRequest{
+		Context:  c.prepareInnertubeContext(webClient),
+		BrowseID: id,
+		Params:   channelAboutParams,
+	}
+	// the join date is only parsed in English, so the page is always requested in English
+	data.Context.Client.HL = "en"
+
+	body, err := c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+webClient.key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	about := &ChannelAbout{ID: id}
+	return about, about.parseChannelAbout(body)
 }
 
 func (c *Client) VideoFromPlaylistEntry(entry *PlaylistEntry) (*Video, error) {
@@ -224,24 +494,46 @@ func (c *Client) GetStream(video *Video, format *Format) (io.ReadCloser, int64,
 
 // GetStreamContext returns the stream and the total size for a specific format with a context.
 func (c *Client) GetStreamContext(ctx context.Context, video *Video, format *Format) (io.ReadCloser, int64, error) {
-	url, err := c.GetStreamURL(video, format)
+	ctx = WithRetryBudget(ctx, c.TotalRetryBudget)
+
+	stream, err := c.getStreamFrom(ctx, video, format, 0)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if c.StreamRetries > 0 && format.ContentLength > 0 {
+		stream = &resilientStream{
+			ctx:    ctx,
+			client: c,
+			video:  video,
+			format: format,
+			stream: stream,
+		}
+	}
+
+	return stream, format.ContentLength, nil
+}
+
+// getStreamFrom returns the stream of a format starting at the byte offset
+func (c *Client) getStreamFrom(ctx context.Context, video *Video, format *Format, offset int64) (io.ReadCloser, error) {
+	url, err := c.GetStreamURLContext(ctx, video, format)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	req, err := newStreamRequest(ctx, url)
+	if err != nil {
+		return nil, err
 	}
 
 	r, w := io.Pipe()
 
-	go c.download(req, w, format)
+	go c.download(ctx, req, w, video, format, offset)
 
-	return r, format.ContentLength, nil
+	return r, nil
 }
 
-func (c *Client) download(req *http.Request, w *io.PipeWriter, format *Format) {
+func (c *Client) download(ctx context.Context, req *http.Request, w *io.PipeWriter, video *Video, format *Format, offset int64) {
 	const chunkSize int64 = 10_000_000
 	// Loads a chunk a returns the written bytes.
 	// Downloading in multiple chunks is much faster:
@@ -273,21 +565,111 @@ func (c *Client) download(req *http.Request, w *io.PipeWriter, format *Format) {
 
 		defer resp.Body.Close()
 
-		io.Copy(w, resp.Body)
+		if _, err = io.Copy(w, resp.Body); err != nil {
+			w.CloseWithError(err)
+		}
 		return
 	}
 
 	//nolint:revive,errcheck
 	// load all the chunks
-	for pos := int64(0); pos < format.ContentLength; {
+	refreshed := false
+	attempts := 0
+	for pos := offset; pos < format.ContentLength; {
 		written, err := loadChunk(pos)
+		// continue a failed chunk where it broke off
+		pos += written
+
+		if err == ErrUnexpectedStatusCode(http.StatusForbidden) && c.AutoRefreshOnStreamError && !refreshed {
+			// refresh only once per chunk, a fresh URL being forbidden as well won't get better
+			refreshed = true
+			if err = spendRetry(ctx, err); err == nil {
+				req, err = c.refreshStreamRequest(ctx, video, format)
+			}
+			if err == nil {
+				continue
+			}
+		}
+		if err != nil && attempts < c.ChunkRetries && retryableError(ctx, err) {
+			if budgetErr := spendRetry(ctx, err); budgetErr != nil {
+				err = budgetErr
+			} else {
+				attempts++
+				c.logf("chunk of video %s at byte %d failed, retry %d: %v", video.ID, pos, attempts, err)
+
+				select {
+				case <-ctx.Done():
+					err = ctx.Err()
+				case <-time.After(time.Duration(attempts) * 500 * time.Millisecond):
+					continue
+				}
+			}
+		}
 		if err != nil {
+			if attempts > 0 {
+				err = &ErrChunkFailed{Start: pos, End: min64(pos+chunkSize, format.ContentLength) - 1, Attempts: attempts + 1, Err: err}
+			}
 			w.CloseWithError(err)
 			return
 		}
 
-		pos += written
+		refreshed = false
+		attempts = 0
+	}
+}
+
+// retryableError reports whether sending a request, like the one of a chunk, again could help
+func retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, io.ErrClosedPipe) {
+		return false
+	}
+
+	var statusErr ErrUnexpectedStatusCode
+	if errors.As(err, &statusErr) {
+		return statusErr >= http.StatusInternalServerError
+	}
+	return true
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// refreshStreamRequest fetches the video again and returns a request for the fresh URL of the format
+func (c *Client) refreshStreamRequest(ctx context.Context, video *Video, format *Format) (*http.Request, error) {
+	c.logf("refreshing stream URL of video %s itag %d", video.ID, format.ItagNo)
+
+	fresh, err := c.videoFromPlayer(ctx, video.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to refresh video: %w", err)
+	}
+
+	freshFormat := fresh.Formats.FindByItag(format.ItagNo)
+	if freshFormat == nil {
+		return nil, fmt.Errorf("unable to refresh video: format with itag %d not found", format.ItagNo)
+	}
+
+	url, err := c.GetStreamURLContext(ctx, fresh, freshFormat)
+	if err != nil {
+		return nil, err
 	}
+
+	return newStreamRequest(ctx, url)
+}
+
+// newStreamRequest returns a request for the media of a stream URL
+func newStreamRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// media is already compressed, and byte ranges must refer to the raw data
+	req.Header.Set("Accept-Encoding", "identity")
+	return req, nil
 }
 
 // GetStreamURL returns the url for a specific format
@@ -297,8 +679,14 @@ func (c *Client) GetStreamURL(video *Video, format *Format) (string, error) {
 
 // GetStreamURLContext returns the url for a specific format with a context
 func (c *Client) GetStreamURLContext(ctx context.Context, video *Video, format *Format) (string, error) {
+	// formats of another video would be rejected by the server with a confusing 403,
+	// videos without formats are accepted as they are usually constructed by hand
+	if len(video.Formats) > 0 && !video.Formats.contains(format) {
+		return "", fmt.Errorf("%w: itag %d of video %s", ErrFormatNotInVideo, format.ItagNo, video.ID)
+	}
+
 	if format.URL != "" {
-		return format.URL, nil
+		return c.addPOToken(format.URL)
 	}
 
 	cipher := format.Cipher
@@ -311,14 +699,42 @@ func (c *Client) GetStreamURLContext(ctx context.Context, video *Video, format *
 		return "", err
 	}
 
-	return uri, err
+	return c.addPOToken(uri)
+}
+
+// addPOToken adds the PO token to a stream URL, if configured
+func (c *Client) addPOToken(streamURL string) (string, error) {
+	if c.POToken == "" {
+		return streamURL, nil
+	}
+
+	uri, err := url.Parse(streamURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := uri.Query()
+	query.Set("pot", c.POToken)
+	uri.RawQuery = query.Encode()
+
+	return uri.String(), nil
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Debug {
+		log.Printf(format, v...)
+	}
 }
 
 // httpDo sends an HTTP request and returns an HTTP response.
 func (c *Client) httpDo(req *http.Request) (*http.Response, error) {
-	client := c.HTTPClient
-	if client == nil {
-		client = http.DefaultClient
+	client, err := c.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Header.Get("Accept-Language") == "" {
+		req.Header.Set("Accept-Language", c.acceptLanguage())
 	}
 
 	if c.Debug {
@@ -334,6 +750,44 @@ func (c *Client) httpDo(req *http.Request) (*http.Response, error) {
 	return res, err
 }
 
+// httpClient returns the HTTP client to use for requests
+func (c *Client) httpClient() (*http.Client, error) {
+	if c.DialContext == nil {
+		if c.HTTPClient != nil {
+			return c.HTTPClient, nil
+		}
+		return http.DefaultClient, nil
+	}
+
+	if client, ok := c.dialClient.Load().(*http.Client); ok {
+		return client, nil
+	}
+
+	client := http.Client{}
+	if c.HTTPClient != nil {
+		client = *c.HTTPClient
+	}
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("DialContext requires an *http.Transport, the HTTP client uses %T", t)
+	}
+
+	transport.DialContext = c.DialContext
+	client.Transport = transport
+
+	// a concurrent first request may have stored its client already
+	if !c.dialClient.CompareAndSwap(nil, &client) {
+		return c.dialClient.Load().(*http.Client), nil
+	}
+	return &client, nil
+}
+
 // httpGet does a HTTP GET request, checks the response to be a 200 OK and returns it
 func (c *Client) httpGet(ctx context.Context, url string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -361,11 +815,16 @@ func (c *Client) httpGetBodyBytes(ctx context.Context, url string) ([]byte, erro
 	}
 	defer resp.Body.Close()
 
-	return io.ReadAll(resp.Body)
+	return readBody(resp)
 }
 
 // httpPost does a HTTP POST request with a body, checks the response to be a 200 OK and returns it
 func (c *Client) httpPost(ctx context.Context, url string, body interface{}) (*http.Response, error) {
+	return c.httpPostWithHeader(ctx, url, body, nil)
+}
+
+// httpPostWithHeader does a HTTP POST request like httpPost with additional headers
+func (c *Client) httpPostWithHeader(ctx context.Context, url string, body interface{}, header http.Header) (*http.Response, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
@@ -376,6 +835,14 @@ func (c *Client) httpPost(ctx context.Context, url string, body interface{}) (*h
 		return nil, err
 	}
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if c.VisitorData != "" {
+		req.Header.Set("X-Goog-Visitor-Id", c.VisitorData)
+	}
+
 	resp, err := c.httpDo(req)
 	if err != nil {
 		return nil, err
@@ -388,13 +855,46 @@ func (c *Client) httpPost(ctx context.Context, url string, body interface{}) (*h
 	return resp, nil
 }
 
-// httpPostBodyBytes reads the whole HTTP body and returns it
+// httpPostBodyBytes reads the whole HTTP body and returns it.
+// Failed requests are sent again up to RequestRetries times.
 func (c *Client) httpPostBodyBytes(ctx context.Context, url string, body interface{}) ([]byte, error) {
-	resp, err := c.httpPost(ctx, url, body)
-	if err != nil {
-		return nil, err
+	for attempts := 0; ; attempts++ {
+		resp, err := c.httpPost(ctx, url, body)
+		if err == nil {
+			defer resp.Body.Close()
+			return readBody(resp)
+		}
+		if attempts >= c.RequestRetries || !retryableError(ctx, err) {
+			return nil, err
+		}
+		if budgetErr := spendRetry(ctx, err); budgetErr != nil {
+			return nil, budgetErr
+		}
+
+		c.logf("request to %s failed, retry %d: %v", url, attempts+1, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempts+1) * 500 * time.Millisecond):
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	return io.ReadAll(resp.Body)
+// readBody reads the whole body of a response and decodes it, if the transport didn't already.
+// This is the case if the HTTP client has compression disabled or requested an encoding itself.
+func readBody(resp *http.Response) ([]byte, error) {
+	switch encoding := resp.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+		return io.ReadAll(resp.Body)
+	case "gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode gzip body: %w", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	default:
+		// e.g. br, which isn't supported by the standard library
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
 }
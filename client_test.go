@@ -1,7 +1,13 @@
 package youtube
 
 import (
+	"compress/gzip"
+	"errors"
 	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -79,6 +85,27 @@ func TestYoutube_findVideoID(t *testing.T) {
 	}
 }
 
+func TestYoutube_ExtractVideoID(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"watch", "https://www.youtube.com/watch?v=rFejpH_tAHM"},
+		{"short url", "https://youtu.be/rFejpH_tAHM"},
+		{"music", "https://music.youtube.com/watch?v=rFejpH_tAHM&feature=share"},
+		{"music with playlist", "https://music.youtube.com/watch?v=rFejpH_tAHM&list=RDAMVMrFejpH_tAHM"},
+		{"podcast episode", "https://music.youtube.com/podcast/rFejpH_tAHM"},
+		{"podcast episode on youtube", "https://www.youtube.com/podcast/rFejpH_tAHM?si=abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ExtractVideoID(tt.url)
+			assert.NoError(t, err)
+			assert.Equal(t, "rFejpH_tAHM", id)
+		})
+	}
+}
+
 func TestGetVideoWithoutManifestURL(t *testing.T) {
 	assert, require := assert.New(t), require.New(t)
 
@@ -198,3 +225,185 @@ func TestClient_httpGetBodyBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetStreamURL_POToken(t *testing.T) {
+	format := &Format{URL: "https://rr1---sn-4g5e6nz7.googlevideo.com/videoplayback?itag=18"}
+
+	url, err := (&Client{}).GetStreamURL(&Video{}, format)
+	require.NoError(t, err)
+	assert.Equal(t, format.URL, url)
+
+	url, err = (&Client{POToken: "token"}).GetStreamURL(&Video{}, format)
+	require.NoError(t, err)
+	assert.Equal(t, format.URL+"&pot=token", url)
+}
+
+func TestClient_GetStreamURL_FormatNotInVideo(t *testing.T) {
+	video := &Video{ID: "BaW_jenozKc", Formats: FormatList{
+		{ItagNo: 18, URL: "https://rr1---sn-4g5e6nz7.googlevideo.com/videoplayback?itag=18&id=first"},
+	}}
+	other := &Video{ID: "9bZkp7q19f0", Formats: FormatList{
+		{ItagNo: 18, URL: "https://rr1---sn-4g5e6nz7.googlevideo.com/videoplayback?itag=18&id=second"},
+	}}
+
+	url, err := (&Client{}).GetStreamURL(video, &video.Formats[0])
+	require.NoError(t, err)
+	assert.Equal(t, video.Formats[0].URL, url)
+
+	// an equal copy of the format is fine
+	format := video.Formats[0]
+	_, err = (&Client{}).GetStreamURL(video, &format)
+	require.NoError(t, err)
+
+	_, err = (&Client{}).GetStreamURL(video, &other.Formats[0])
+	assert.ErrorIs(t, err, ErrFormatNotInVideo)
+
+	_, _, err = (&Client{}).GetStream(video, &other.Formats[0])
+	assert.ErrorIs(t, err, ErrFormatNotInVideo)
+}
+
+func TestClient_httpGetBodyBytes_ContentEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			_, _ = gz.Write([]byte(`{"ok":true}`))
+			_ = gz.Close()
+		case "/br":
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte{0x0b, 0x02, 0x80})
+		}
+	}))
+	defer server.Close()
+
+	// the transport only decodes responses if it requested the compression itself
+	for _, disableCompression := range []bool{false, true} {
+		client := &Client{HTTPClient: &http.Client{Transport: &http.Transport{DisableCompression: disableCompression}}}
+
+		body, err := client.httpGetBodyBytes(context.Background(), server.URL+"/gzip")
+		require.NoError(t, err)
+		assert.Equal(t, `{"ok":true}`, string(body))
+
+		_, err = client.httpGetBodyBytes(context.Background(), server.URL+"/br")
+		assert.EqualError(t, err, "unsupported content encoding: br")
+	}
+}
+
+func TestClient_GetStream_NoCompression(t *testing.T) {
+	data := []byte{0x1f, 0x8b, 0x08, 0x00} // media which happens to look like gzip
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "identity", r.Header.Get("Accept-Encoding"))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	stream, _, err := (&Client{}).GetStream(&Video{}, &Format{URL: server.URL, ContentLength: int64(len(data))})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	got, err := io.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestClient_Language(t *testing.T) {
+	var acceptLanguage string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptLanguage = r.Header.Get("Accept-Language")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		client         Client
+		hl             string
+		acceptLanguage string
+	}{
+		{Client{}, "en", "en"},
+		{Client{Language: "de"}, "de", "de"},
+		{Client{Language: "de", AcceptLanguage: "fr-FR,fr;q=0.9"}, "de", "fr-FR,fr;q=0.9"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.hl, tt.client.prepareInnertubeContext(webClient).Client.HL)
+
+		_, err := tt.client.httpGetBodyBytes(context.Background(), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, tt.acceptLanguage, acceptLanguage)
+	}
+}
+
+type staticDislikes map[string]int64
+
+func (d staticDislikes) Dislikes(_ context.Context, videoID string) (int64, error) {
+	if dislikes, ok := d[videoID]; ok {
+		return dislikes, nil
+	}
+	return 0, errors.New("unknown video")
+}
+
+func TestClient_extendFromDislikeProvider(t *testing.T) {
+	v := &Video{ID: "rFejpH_tAHM"}
+	(&Client{}).extendFromDislikeProvider(context.Background(), v)
+	assert.Zero(t, v.Dislikes)
+
+	client := &Client{DislikeProvider: staticDislikes{"rFejpH_tAHM": 42}}
+	client.extendFromDislikeProvider(context.Background(), v)
+	assert.EqualValues(t, 42, v.Dislikes)
+
+	v = &Video{ID: "BaW_jenozKc"}
+	client.extendFromDislikeProvider(context.Background(), v)
+	assert.Zero(t, v.Dislikes)
+}
+
+func TestClient_DialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer server.Close()
+
+	var dialed []string
+	client := &Client{
+		HTTPClient: &http.Client{Timeout: time.Second},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			// pin all connections to the test server
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	body, err := client.httpGetBodyBytes(context.Background(), "http://www.youtube.com/")
+	require.NoError(t, err)
+	assert.Equal(t, "www.youtube.com", string(body))
+	assert.Equal(t, []string{"www.youtube.com:80"}, dialed)
+	assert.Nil(t, client.HTTPClient.Transport, "the HTTP client isn't modified")
+
+	client = &Client{
+		HTTPClient:  &http.Client{Transport: roundTripFunc(nil)},
+		DialContext: (&net.Dialer{}).DialContext,
+	}
+	_, err = client.httpGetBodyBytes(context.Background(), "http://www.youtube.com/")
+	assert.ErrorContains(t, err, "DialContext requires an *http.Transport")
+}
+
+func TestClient_GetVideo_TotalRetryBudget(t *testing.T) {
+	// the player endpoint keeps failing, only the budget limits the retries
+	requests := 0
+	transport := roundTripFunc(func(req *http.Request) *http.Response {
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/embed/"):
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`"jsUrl":"/s/player/abcdef/player_ias.vflset/en_US/base.js"`))}
+		case strings.HasPrefix(req.URL.Path, "/s/player/"):
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`var a={b:1,signatureTimestamp:19000}`))}
+		}
+		requests++
+		return &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+	})
+
+	client := Client{RequestRetries: 5, TotalRetryBudget: 1, HTTPClient: &http.Client{Transport: transport}}
+	_, err := client.GetVideo("https://www.youtube.com/watch?v=test")
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, 2, requests, "the request is retried once")
+}
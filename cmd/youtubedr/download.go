@@ -25,6 +25,7 @@ var (
 	ffmpegCheck error
 	outputFile  string
 	outputDir   string
+	remuxToMP4  bool
 )
 
 func init() {
@@ -32,6 +33,7 @@ func init() {
 
 	downloadCmd.Flags().StringVarP(&outputFile, "filename", "o", "", "The output file, the default is genated by the video title.")
 	downloadCmd.Flags().StringVarP(&outputDir, "directory", "d", ".", "The output directory.")
+	downloadCmd.Flags().BoolVar(&remuxToMP4, "remux-mp4", false, "Remux webm formats into mp4 via ffmpeg, without re-encoding.")
 	addQualityFlag(downloadCmd.Flags())
 	addMimeTypeFlag(downloadCmd.Flags())
 }
@@ -44,6 +46,13 @@ func download(id string) error {
 
 	log.Println("download to directory", outputDir)
 
+	if remuxToMP4 {
+		if err := checkFFMPEG(); err != nil {
+			return err
+		}
+		downloader.RemuxToMP4 = true
+	}
+
 	if strings.HasPrefix(outputQuality, "hd") {
 		if err := checkFFMPEG(); err != nil {
 			return err
@@ -51,18 +51,16 @@ var infoCmd = &cobra.Command{
 			Description: video.Description,
 		}
 
-		for _, format := range video.Formats {
+		for i := range video.Formats {
+			format := &video.Formats[i]
 			bitrate := format.AverageBitrate
 			if bitrate == 0 {
 				// Some formats don't have the average bitrate
 				bitrate = format.Bitrate
 			}
 
-			size := format.ContentLength
-			if size == 0 {
-				// Some formats don't have this information
-				size = int64(float64(bitrate) * video.Duration.Seconds() / 8)
-			}
+			// Some formats don't have the content length, it gets estimated then
+			size := video.EstimatedDownloadSize(format, nil)
 
 			videoInfo.Formats = append(videoInfo.Formats, VideoFormat{
 				Itag:          format.ItagNo,
@@ -0,0 +1,245 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cue is a piece of caption text and the time span it is displayed
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+
+	// Words holds word-level timing, only available for the json3 format.
+	// It is empty if the captions have only segment-level timing.
+	Words []Word
+}
+
+// Word is a single word or syllable of a cue
+type Word struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// parseCues parses a timedtext response, either XML (legacy or format 3) or json3
+func parseCues(data []byte) ([]Cue, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if data[0] == '{' {
+		return parseJSON3Cues(data)
+	}
+	return parseXMLCues(data)
+}
+
+// timedTextXML covers the legacy format (<transcript><text start="1.2" dur="3.4">)
+// and format 3 (<timedtext format="3"><body><p t="1200" d="3400">)
+type timedTextXML struct {
+	Texts []struct {
+		Start float64 `xml:"start,attr"`
+		Dur   float64 `xml:"dur,attr"`
+		Text  string  `xml:",chardata"`
+	} `xml:"text"`
+	Paragraphs []struct {
+		T        int64  `xml:"t,attr"`
+		D        int64  `xml:"d,attr"`
+		Text     string `xml:",chardata"`
+		Segments []struct {
+			Text string `xml:",chardata"`
+		} `xml:"s"`
+	} `xml:"body>p"`
+}
+
+func parseXMLCues(data []byte) ([]Cue, error) {
+	var tt timedTextXML
+	if err := xml.Unmarshal(data, &tt); err != nil {
+		return nil, fmt.Errorf("unable to parse timedtext XML: %w", err)
+	}
+
+	cues := make([]Cue, 0, len(tt.Texts)+len(tt.Paragraphs))
+	for _, text := range tt.Texts {
+		start := secondsToDuration(text.Start)
+		cues = append(cues, Cue{
+			Start: start,
+			End:   start + secondsToDuration(text.Dur),
+			// the legacy format escapes HTML entities twice
+			Text: html.UnescapeString(text.Text),
+		})
+	}
+
+	for _, p := range tt.Paragraphs {
+		text := p.Text
+		if len(p.Segments) > 0 {
+			text = ""
+			for _, s := range p.Segments {
+				text += s.Text
+			}
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		start := time.Duration(p.T) * time.Millisecond
+		cues = append(cues, Cue{
+			Start: start,
+			End:   start + time.Duration(p.D)*time.Millisecond,
+			Text:  text,
+		})
+	}
+
+	return cues, nil
+}
+
+type timedTextJSON3 struct {
+	Events []struct {
+		TStartMs    int64 `json:"tStartMs"`
+		DDurationMs int64 `json:"dDurationMs"`
+		Segs        []struct {
+			UTF8      string `json:"utf8"`
+			TOffsetMs int64  `json:"tOffsetMs"`
+		} `json:"segs"`
+	} `json:"events"`
+}
+
+func parseJSON3Cues(data []byte) ([]Cue, error) {
+	var tt timedTextJSON3
+	if err := json.Unmarshal(data, &tt); err != nil {
+		return nil, fmt.Errorf("unable to parse timedtext json3: %w", err)
+	}
+
+	cues := make([]Cue, 0, len(tt.Events))
+	for _, event := range tt.Events {
+		var text string
+		for _, seg := range event.Segs {
+			text += seg.UTF8
+		}
+
+		// events without text only control the layout
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		start := time.Duration(event.TStartMs) * time.Millisecond
+		cue := Cue{
+			Start: start,
+			End:   start + time.Duration(event.DDurationMs)*time.Millisecond,
+			Text:  text,
+		}
+
+		// a single segment has no timing apart from the event itself
+		if len(event.Segs) > 1 {
+			for i, seg := range event.Segs {
+				word := Word{
+					Start: start + time.Duration(seg.TOffsetMs)*time.Millisecond,
+					End:   cue.End,
+					Text:  seg.UTF8,
+				}
+				if i+1 < len(event.Segs) {
+					word.End = start + time.Duration(event.Segs[i+1].TOffsetMs)*time.Millisecond
+				}
+				cue.Words = append(cue.Words, word)
+			}
+		}
+
+		cues = append(cues, cue)
+	}
+
+	return cues, nil
+}
+
+// WriteSRT writes the cues in the SubRip format
+func WriteSRT(w io.Writer, cues []Cue) error {
+	for i, cue := range cues {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1, formatCueTime(cue.Start, ','), formatCueTime(cue.End, ','), cue.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteVTT writes the cues in the WebVTT format
+func WriteVTT(w io.Writer, cues []Cue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+
+	for _, cue := range cues {
+		_, err := fmt.Fprintf(w, "%s --> %s\n%s\n\n", formatCueTime(cue.Start, '.'), formatCueTime(cue.End, '.'), cue.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlayCues sends the cues on the returned channel at the time they appear when playback began at start,
+// as for a live caption display. The speed multiplies the playback rate, values <= 0 mean normal speed.
+// Cues which already ended are skipped. The channel is closed after the last cue or when ctx is done.
+func PlayCues(ctx context.Context, cues []Cue, start time.Time, speed float64) <-chan Cue {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	sorted := make([]Cue, len(cues))
+	copy(sorted, cues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	ch := make(chan Cue)
+	go func() {
+		defer close(ch)
+
+		at := func(offset time.Duration) time.Time {
+			return start.Add(time.Duration(float64(offset) / speed))
+		}
+
+		for _, cue := range sorted {
+			if cue.End > cue.Start && time.Now().After(at(cue.End)) {
+				continue
+			}
+
+			timer := time.NewTimer(time.Until(at(cue.Start)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- cue:
+			}
+		}
+	}()
+
+	return ch
+}
+
+// formatCueTime formats the time as hh:mm:ss followed by the separator and milliseconds
+func formatCueTime(d time.Duration, separator rune) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,96 @@
+package youtube
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCues(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Cue
+	}{
+		{
+			name: "legacy",
+			data: `<?xml version="1.0" encoding="utf-8" ?><transcript><text start="0.5" dur="1.25">Hello &amp;#39;world&amp;#39;</text><text start="2" dur="1">again</text></transcript>`,
+			want: []Cue{
+				{Start: 500 * time.Millisecond, End: 1750 * time.Millisecond, Text: "Hello 'world'"},
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: "again"},
+			},
+		},
+		{
+			name: "format 3",
+			data: `<?xml version="1.0" encoding="utf-8" ?><timedtext format="3"><body><p t="500" d="1250">Hello</p><p t="2000" d="1000"><s>to</s><s t="400"> you</s></p><p t="3000" d="10">
+</p></body></timedtext>`,
+			want: []Cue{
+				{Start: 500 * time.Millisecond, End: 1750 * time.Millisecond, Text: "Hello"},
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: "to you"},
+			},
+		},
+		{
+			name: "json3",
+			data: `{"wireMagic":"pb3","events":[{"tStartMs":0,"dDurationMs":5000,"id":1},{"tStartMs":500,"dDurationMs":1250,"segs":[{"utf8":"Hello"},{"utf8":" world","tOffsetMs":400}]},{"tStartMs":1000,"segs":[{"utf8":"\n"}]},{"tStartMs":2000,"dDurationMs":1000,"segs":[{"utf8":"no words"}]}]}`,
+			want: []Cue{
+				{Start: 500 * time.Millisecond, End: 1750 * time.Millisecond, Text: "Hello world", Words: []Word{
+					{Start: 500 * time.Millisecond, End: 900 * time.Millisecond, Text: "Hello"},
+					{Start: 900 * time.Millisecond, End: 1750 * time.Millisecond, Text: " world"},
+				}},
+				{Start: 2 * time.Second, End: 3 * time.Second, Text: "no words"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues, err := parseCues([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cues)
+		})
+	}
+}
+
+func TestWriteCues(t *testing.T) {
+	cues := []Cue{
+		{Start: 500 * time.Millisecond, End: 1750 * time.Millisecond, Text: "Hello"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second, End: time.Hour + 2*time.Minute + 4*time.Second + 5*time.Millisecond, Text: "two\nlines"},
+	}
+
+	var srt strings.Builder
+	require.NoError(t, WriteSRT(&srt, cues))
+	assert.Equal(t, "1\n00:00:00,500 --> 00:00:01,750\nHello\n\n2\n01:02:03,000 --> 01:02:04,005\ntwo\nlines\n\n", srt.String())
+
+	var vtt strings.Builder
+	require.NoError(t, WriteVTT(&vtt, cues))
+	assert.Equal(t, "WEBVTT\n\n00:00:00.500 --> 00:00:01.750\nHello\n\n01:02:03.000 --> 01:02:04.005\ntwo\nlines\n\n", vtt.String())
+}
+
+func TestPlayCues(t *testing.T) {
+	cues := []Cue{
+		{Start: 400 * time.Millisecond, End: 600 * time.Millisecond, Text: "third"},
+		{Start: 0, End: 100 * time.Millisecond, Text: "expired"},
+		{Start: 200 * time.Millisecond, End: 2 * time.Second, Text: "second"},
+		{Start: 0, Text: "first"},
+	}
+
+	// cues start at half the offsets at double speed
+	start := time.Now().Add(-100 * time.Millisecond)
+	var texts []string
+	for cue := range PlayCues(context.Background(), cues, start, 2) {
+		assert.False(t, time.Now().Before(start.Add(cue.Start/2)), cue.Text)
+		texts = append(texts, cue.Text)
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, texts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := PlayCues(ctx, []Cue{{Start: 0, Text: "now"}, {Start: time.Hour, Text: "later"}}, time.Now(), 1)
+	assert.Equal(t, "now", (<-ch).Text)
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
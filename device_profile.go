@@ -0,0 +1,135 @@
+package youtube
+
+import (
+	"mime"
+	"strings"
+)
+
+// DeviceProfile describes the formats a playback device supports.
+// Empty lists and zero limits don't restrict the formats.
+type DeviceProfile struct {
+	Name        string
+	Containers  []string // e.g. "mp4" or "webm"
+	VideoCodecs []string // codec prefixes, e.g. "avc1" or "vp9"
+	AudioCodecs []string // codec prefixes, e.g. "mp4a" or "opus"
+	MaxHeight   int
+	MaxFPS      int
+
+	// RequireAudio only selects formats with video and audio, for devices playing a single URL
+	RequireAudio bool
+}
+
+// Built-in profiles of common devices
+var (
+	ProfileChromecast = DeviceProfile{
+		Name:         "Chromecast",
+		Containers:   []string{"mp4", "webm"},
+		VideoCodecs:  []string{"avc1", "vp9", "vp09"},
+		AudioCodecs:  []string{"mp4a", "opus"},
+		MaxHeight:    1080,
+		MaxFPS:       60,
+		RequireAudio: true,
+	}
+	ProfileChromecastUltra = DeviceProfile{
+		Name:         "Chromecast Ultra",
+		Containers:   []string{"mp4", "webm"},
+		VideoCodecs:  []string{"avc1", "vp9", "vp09"},
+		AudioCodecs:  []string{"mp4a", "opus"},
+		MaxHeight:    2160,
+		MaxFPS:       60,
+		RequireAudio: true,
+	}
+	ProfileAppleTV = DeviceProfile{
+		Name:         "Apple TV",
+		Containers:   []string{"mp4"},
+		VideoCodecs:  []string{"avc1", "hev1", "hvc1"},
+		AudioCodecs:  []string{"mp4a"},
+		MaxHeight:    2160,
+		MaxFPS:       60,
+		RequireAudio: true,
+	}
+)
+
+// Supports reports whether the device can play the format
+func (p DeviceProfile) Supports(format *Format) bool {
+	container, codecs := parseMimeType(format.MimeType)
+	if container == "" || (len(p.Containers) > 0 && !containsString(p.Containers, container)) {
+		return false
+	}
+
+	if p.MaxHeight > 0 && format.Height > p.MaxHeight {
+		return false
+	}
+	if p.MaxFPS > 0 && format.FPS > p.MaxFPS {
+		return false
+	}
+	if p.RequireAudio && (format.AudioChannels == 0 || format.Height == 0) {
+		return false
+	}
+
+	for _, codec := range codecs {
+		supported := p.VideoCodecs
+		if hasCodecPrefix(audioCodecs, codec) {
+			supported = p.AudioCodecs
+		}
+		if len(supported) > 0 && !hasCodecPrefix(supported, codec) {
+			return false
+		}
+	}
+	return true
+}
+
+// BestFormatForProfile returns the best video format the device can play or nil if there is none
+func (v *Video) BestFormatForProfile(profile DeviceProfile) *Format {
+	var formats FormatList
+	for i := range v.Formats {
+		if v.Formats[i].Height > 0 && profile.Supports(&v.Formats[i]) {
+			formats = append(formats, v.Formats[i])
+		}
+	}
+	if len(formats) == 0 {
+		return nil
+	}
+
+	formats.Sort()
+	return v.Formats.FindByItag(formats[0].ItagNo)
+}
+
+// parseMimeType returns the container and codecs of a mime type like `video/mp4; codecs="avc1.42001E, mp4a.40.2"`
+func parseMimeType(mimeType string) (string, []string) {
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return "", nil
+	}
+
+	var codecs []string
+	for _, codec := range strings.Split(params["codecs"], ",") {
+		if codec = strings.TrimSpace(codec); codec != "" {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	return mediaType[strings.Index(mediaType, "/")+1:], codecs
+}
+
+// audioCodecs are the prefixes of the audio codecs used by YouTube, all others are video codecs
+var audioCodecs = []string{"mp4a", "opus", "vorbis", "ac-3", "ec-3"}
+
+// hasCodecPrefix reports whether a codec like "avc1.64001F" matches one of the prefixes
+func hasCodecPrefix(prefixes []string, codec string) bool {
+	for _, prefix := range prefixes {
+		if codec == prefix || strings.HasPrefix(codec, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
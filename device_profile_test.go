@@ -0,0 +1,43 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideo_BestFormatForProfile(t *testing.T) {
+	video := &Video{Formats: FormatList{
+		{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Width: 640, Height: 360, FPS: 30, AudioChannels: 2},
+		{ItagNo: 22, MimeType: `video/mp4; codecs="avc1.64001F, mp4a.40.2"`, Width: 1280, Height: 720, FPS: 30, AudioChannels: 2},
+		{ItagNo: 43, MimeType: `video/webm; codecs="vp8.0, vorbis"`, Width: 1920, Height: 1080, FPS: 30, AudioChannels: 2},
+		{ItagNo: 315, MimeType: `video/webm; codecs="vp9"`, Width: 3840, Height: 2160, FPS: 60},
+		{ItagNo: 401, MimeType: `video/mp4; codecs="av01.0.12M.08"`, Width: 3840, Height: 2160, FPS: 30},
+		{ItagNo: 137, MimeType: `video/mp4; codecs="avc1.640028"`, Width: 1920, Height: 1080, FPS: 30},
+		{ItagNo: 140, MimeType: `audio/mp4; codecs="mp4a.40.2"`, AudioChannels: 2},
+	}}
+
+	format := video.BestFormatForProfile(ProfileChromecast)
+	require.NotNil(t, format)
+	assert.Equal(t, 22, format.ItagNo)
+	assert.Same(t, &video.Formats[1], format)
+
+	custom := DeviceProfile{Name: "4K VP9", Containers: []string{"webm"}, VideoCodecs: []string{"vp9"}}
+	format = video.BestFormatForProfile(custom)
+	require.NotNil(t, format)
+	assert.Equal(t, 315, format.ItagNo)
+
+	custom.MaxFPS = 30
+	assert.Nil(t, video.BestFormatForProfile(custom))
+
+	// the audio format is never selected
+	assert.Equal(t, 315, video.BestFormatForProfile(DeviceProfile{}).ItagNo)
+}
+
+func TestDeviceProfile_Supports(t *testing.T) {
+	assert.True(t, ProfileAppleTV.Supports(&Format{MimeType: `video/mp4; codecs="avc1.64001F, mp4a.40.2"`, Height: 720, AudioChannels: 2}))
+	assert.False(t, ProfileAppleTV.Supports(&Format{MimeType: `video/webm; codecs="vp9, opus"`, Height: 720, AudioChannels: 2}))
+	assert.False(t, ProfileAppleTV.Supports(&Format{MimeType: `video/mp4; codecs="avc1.640028"`, Height: 1080}))
+	assert.False(t, ProfileAppleTV.Supports(&Format{MimeType: "invalid"}))
+}
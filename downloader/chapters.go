@@ -0,0 +1,81 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// SplitByChapters cuts a downloaded file into one file per chapter via ffmpeg, without re-encoding.
+// The files are named by the number and the sanitized title of the chapter and keep the extension
+// of the input. Each chapter lasts until the next one starts, the last one until the end of the file.
+// As the streams are copied, the cuts snap to the nearest keyframes. The chapters of a video are
+// only set by Client.GetVideoDetails.
+func SplitByChapters(inputPath string, chapters []youtube.Chapter, outDir string) error {
+	if len(chapters) == 0 {
+		return fmt.Errorf("no chapters to split %s by", inputPath)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	for i, chapter := range chapters {
+		var end time.Duration
+		if i+1 < len(chapters) {
+			end = chapters[i+1].Start
+		}
+
+		outputFile := filepath.Join(outDir, chapterFilename(i, chapter, filepath.Ext(inputPath)))
+
+		var stderr bytes.Buffer
+		//nolint:gosec
+		ffmpegCmd := exec.Command("ffmpeg", chapterArgs(inputPath, outputFile, chapter.Start, end)...)
+		ffmpegCmd.Stderr = &stderr
+
+		if err := ffmpegCmd.Run(); err != nil {
+			return fmt.Errorf("ffmpeg failed to cut chapter %d %q: %w: %s", i+1, chapter.Title, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return nil
+}
+
+// chapterFilename returns the name of the file of the i-th chapter
+func chapterFilename(i int, chapter youtube.Chapter, extension string) string {
+	title := SanitizeFilename(chapter.Title)
+	if strings.TrimSpace(title) == "" {
+		title = "Chapter " + strconv.Itoa(i+1)
+	}
+	return fmt.Sprintf("%02d %s%s", i+1, title, extension)
+}
+
+// chapterArgs returns the ffmpeg arguments to copy the part from start to end, a zero end is the end of the file.
+// Both are input options, so ffmpeg seeks in the input instead of decoding up to start, and end stays an
+// absolute position rather than one relative to start.
+func chapterArgs(inputFile, outputFile string, start, end time.Duration) []string {
+	args := []string{"-y",
+		"-ss", formatSeconds(start),
+	}
+	if end > 0 {
+		args = append(args, "-to", formatSeconds(end))
+	}
+
+	return append(args,
+		"-i", inputFile,
+		"-map", "0",
+		"-c", "copy", // Just copy without re-encoding
+		outputFile,
+		"-loglevel", "error",
+	)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
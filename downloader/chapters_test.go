@@ -0,0 +1,32 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_chapterFilename(t *testing.T) {
+	assert.Equal(t, "01 Intro.mp4", chapterFilename(0, youtube.Chapter{Title: "Intro"}, ".mp4"))
+	assert.Equal(t, "12 Q&A what's next.m4a", chapterFilename(11, youtube.Chapter{Title: "Q&A / what's next?"}, ".m4a"))
+	assert.Equal(t, "03 Chapter 3.mp4", chapterFilename(2, youtube.Chapter{Title: "?"}, ".mp4"))
+}
+
+func Test_chapterArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-y", "-ss", "65.000", "-to", "130.500", "-i", "in.mp4",
+		"-map", "0", "-c", "copy", "out.mp4", "-loglevel", "error",
+	}, chapterArgs("in.mp4", "out.mp4", 65*time.Second, 130500*time.Millisecond))
+
+	// the last chapter lasts until the end of the file
+	assert.Equal(t, []string{
+		"-y", "-ss", "130.500", "-i", "in.mp4",
+		"-map", "0", "-c", "copy", "out.mp4", "-loglevel", "error",
+	}, chapterArgs("in.mp4", "out.mp4", 130500*time.Millisecond, 0))
+}
+
+func TestSplitByChapters_NoChapters(t *testing.T) {
+	assert.Error(t, SplitByChapters("in.mp4", nil, t.TempDir()))
+}
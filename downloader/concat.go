@@ -0,0 +1,133 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// AudioTrack is the audio format of a video to download
+type AudioTrack struct {
+	Video  *youtube.Video
+	Format *youtube.Format
+}
+
+// DownloadConcatenatedAudio downloads the audio of the tracks and concatenates them in order into outputFile via ffmpeg.
+// The streams are copied if all formats share the same codec and sample rate and reencode is false.
+// Otherwise they are re-encoded to the highest sample rate, using the codec ffmpeg picks for the file extension.
+func (dl *Downloader) DownloadConcatenatedAudio(ctx context.Context, outputFile string, tracks []AudioTrack, reencode bool) error {
+	if len(tracks) == 0 {
+		return fmt.Errorf("no audio tracks to concatenate")
+	}
+	if outputFile == "" {
+		return fmt.Errorf("an output file is required to concatenate audio tracks")
+	}
+
+	// all downloads share the retry budget
+	ctx = youtube.WithRetryBudget(ctx, dl.TotalRetryBudget)
+
+	destFile, err := dl.getOutputFile(tracks[0].Video, tracks[0].Format, outputFile)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, len(tracks))
+	formats := make([]*youtube.Format, len(tracks))
+	for i, track := range tracks {
+		file, err := dl.createTemp("youtube_*" + pickIdealFileExtension(track.Format.MimeType))
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file.Name())
+
+		dl.logf("Downloading audio %d/%d of video '%s'...", i+1, len(tracks), track.Video.Title)
+		_, err = dl.videoDLWorker(ctx, file, track.Video, track.Format)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("video %s: %w", track.Video.ID, err)
+		}
+
+		files[i] = file.Name()
+		formats[i] = track.Format
+	}
+
+	var args []string
+	if reencode || !sameAudioEncoding(formats) {
+		args = reencodeConcatArgs(files, formats)
+	} else {
+		// the concat demuxer reads the files from a list
+		list, err := dl.createTemp("youtube_*.txt")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(list.Name())
+
+		err = writeConcatList(list, files)
+		if closeErr := list.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("unable to write the concat list: %w", err)
+		}
+
+		args = []string{"-f", "concat", "-safe", "0", "-i", list.Name(), "-c", "copy"}
+	}
+
+	//nolint:gosec
+	ffmpegCmd := exec.CommandContext(ctx, "ffmpeg", append(append([]string{"-y"}, args...), destFile, "-loglevel", "warning")...)
+	ffmpegCmd.Stderr = os.Stderr
+	ffmpegCmd.Stdout = os.Stdout
+	dl.logf("concatenating %d audio tracks to %s", len(files), destFile)
+
+	return ffmpegCmd.Run()
+}
+
+// writeConcatList writes the files in the list format of the ffmpeg concat demuxer
+func writeConcatList(w io.Writer, files []string) error {
+	for _, file := range files {
+		if _, err := fmt.Fprintf(w, "file '%s'\n", strings.ReplaceAll(file, "'", `'\''`)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameAudioEncoding reports whether the formats can be concatenated without re-encoding
+func sameAudioEncoding(formats []*youtube.Format) bool {
+	for _, format := range formats[1:] {
+		if format.MimeType != formats[0].MimeType || format.AudioSampleRate != formats[0].AudioSampleRate {
+			return false
+		}
+	}
+	return true
+}
+
+// reencodeConcatArgs returns the ffmpeg arguments to resample the files to the highest sample rate and concatenate them
+func reencodeConcatArgs(files []string, formats []*youtube.Format) []string {
+	sampleRate := 0
+	for _, format := range formats {
+		if rate, _ := strconv.Atoi(format.AudioSampleRate); rate > sampleRate {
+			sampleRate = rate
+		}
+	}
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+
+	var args []string
+	var filter, inputs strings.Builder
+	for i, file := range files {
+		args = append(args, "-i", file)
+		fmt.Fprintf(&filter, "[%d:a]aresample=%d[a%d];", i, sampleRate, i)
+		fmt.Fprintf(&inputs, "[a%d]", i)
+	}
+	fmt.Fprintf(&filter, "%sconcat=n=%d:v=0:a=1[out]", inputs.String(), len(files))
+
+	return append(args, "-filter_complex", filter.String(), "-map", "[out]")
+}
@@ -0,0 +1,56 @@
+package downloader
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kkdai/youtube/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sameAudioEncoding(t *testing.T) {
+	opus48k := &youtube.Format{MimeType: `audio/webm; codecs="opus"`, AudioSampleRate: "48000"}
+	aac44k := &youtube.Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`, AudioSampleRate: "44100"}
+	aac48k := &youtube.Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`, AudioSampleRate: "48000"}
+
+	assert.True(t, sameAudioEncoding([]*youtube.Format{opus48k, opus48k}))
+	assert.False(t, sameAudioEncoding([]*youtube.Format{opus48k, aac48k}))
+	assert.False(t, sameAudioEncoding([]*youtube.Format{aac44k, aac48k}))
+}
+
+func Test_reencodeConcatArgs(t *testing.T) {
+	args := reencodeConcatArgs(
+		[]string{"a.m4a", "b.m4a"},
+		[]*youtube.Format{{AudioSampleRate: "44100"}, {AudioSampleRate: "48000"}},
+	)
+
+	assert.Equal(t, []string{
+		"-i", "a.m4a",
+		"-i", "b.m4a",
+		"-filter_complex", "[0:a]aresample=48000[a0];[1:a]aresample=48000[a1];[a0][a1]concat=n=2:v=0:a=1[out]",
+		"-map", "[out]",
+	}, args)
+}
+
+// shortWriter fails after accepting n bytes
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		written := w.n
+		w.n = 0
+		return written, io.ErrShortWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func Test_writeConcatList(t *testing.T) {
+	var list strings.Builder
+	require.NoError(t, writeConcatList(&list, []string{"/tmp/a.m4a", "/tmp/it's.m4a"}))
+	assert.Equal(t, "file '/tmp/a.m4a'\nfile '/tmp/it'\\''s.m4a'\n", list.String())
+
+	assert.ErrorIs(t, writeConcatList(&shortWriter{n: 20}, []string{"/tmp/a.m4a", "/tmp/b.m4a"}), io.ErrShortWrite)
+}
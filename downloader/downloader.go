@@ -1,14 +1,18 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/kkdai/youtube/v2"
 	"github.com/vbauerster/mpb/v5"
@@ -19,12 +23,80 @@ import (
 type Downloader struct {
 	youtube.Client
 	OutputDir string // optional directory to store the files
+
+	// OnProgress is called at most once per second during a download and once when it has finished.
+	// It can be used to react on throttled downloads, e.g. by cancelling and retrying them.
+	OnProgress func(v *youtube.Video, format *youtube.Format, t Throughput)
+
+	// ThrottleThreshold is the speed in bytes per second below which a download is reported as throttled.
+	// If zero, twice the playback speed of the format (its bitrate) is used, as throttled downloads
+	// run at about the playback speed.
+	ThrottleThreshold float64
+
+	// MaxDuration limits the time spent on a single call to Download or DownloadComposite.
+	// When it's reached the data received so far is kept and an *ErrPartialDownload is returned.
+	MaxDuration time.Duration
+
+	// RemuxToMP4 converts webm downloads into an mp4 container via ffmpeg, without re-encoding.
+	// Formats which already are mp4 are downloaded as they are.
+	RemuxToMP4 bool
+}
+
+// ErrPartialDownload is returned when a download was stopped by Downloader.MaxDuration.
+// The file contains the first BytesWritten bytes of the stream and isn't removed.
+// File is empty if nothing could be written, e.g. a composite download without audio data.
+type ErrPartialDownload struct {
+	File         string
+	BytesWritten int64
+	MaxDuration  time.Duration
+}
+
+func (err ErrPartialDownload) Error() string {
+	if err.File == "" {
+		return fmt.Sprintf("download stopped after %s, %d bytes received but no file written", err.MaxDuration, err.BytesWritten)
+	}
+	return fmt.Sprintf("download stopped after %s, %d bytes written to %s", err.MaxDuration, err.BytesWritten, err.File)
+}
+
+// ErrVideosFailed is returned by DownloadChannelSince with the errors of the videos it skipped
+type ErrVideosFailed struct {
+	Errors []error
+}
+
+func (err *ErrVideosFailed) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, videoErr := range err.Errors {
+		messages[i] = videoErr.Error()
+	}
+	return fmt.Sprintf("%d videos failed: %s", len(err.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of the videos, for errors.Is and errors.As
+func (err *ErrVideosFailed) Unwrap() []error {
+	return err.Errors
+}
+
+// withMaxDuration returns a context which is cancelled when MaxDuration has passed
+func (dl *Downloader) withMaxDuration(ctx context.Context) (context.Context, context.CancelFunc) {
+	if dl.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, dl.MaxDuration)
+}
+
+// maxDurationReached reports whether ctx was stopped by MaxDuration and not by its parent
+func maxDurationReached(parent, ctx context.Context) bool {
+	return parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
 }
 
 func (dl *Downloader) getOutputFile(v *youtube.Video, format *youtube.Format, outputFile string) (string, error) {
 	if outputFile == "" {
 		outputFile = SanitizeFilename(v.Title)
-		outputFile += pickIdealFileExtension(format.MimeType)
+		if dl.remux(format) {
+			outputFile += ".mp4"
+		} else {
+			outputFile += pickIdealFileExtension(format.MimeType)
+		}
 	}
 
 	if dl.OutputDir != "" {
@@ -37,27 +109,94 @@ func (dl *Downloader) getOutputFile(v *youtube.Video, format *youtube.Format, ou
 	return outputFile, nil
 }
 
+// createTemp creates a temporary file in the TempDir, or in os.TempDir if it's empty
+func (dl *Downloader) createTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(dl.TempDir, pattern)
+}
+
 // Download : Starting download video by arguments.
-func (dl *Downloader) Download(ctx context.Context, v *youtube.Video, format *youtube.Format, outputFile string) error {
+func (dl *Downloader) Download(parent context.Context, v *youtube.Video, format *youtube.Format, outputFile string) error {
+	ctx, cancel := dl.withMaxDuration(parent)
+	defer cancel()
+
 	dl.logf("Video '%s' - Quality '%s' - Codec '%s'", v.Title, format.QualityLabel, format.MimeType)
 	destFile, err := dl.getOutputFile(v, format, outputFile)
 	if err != nil {
 		return err
 	}
 
-	// Create output file
-	out, err := os.Create(destFile)
+	// Create output file, or a temporary one to remux
+	var out *os.File
+	if dl.remux(format) {
+		out, err = dl.createTemp("youtube_*.webm")
+		if err == nil {
+			defer os.Remove(out.Name())
+		}
+	} else {
+		out, err = os.Create(destFile)
+	}
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
 	dl.logf("Download to file=%s", destFile)
-	return dl.videoDLWorker(ctx, out, v, format)
+	written, err := dl.videoDLWorker(ctx, out, v, format)
+	partial := err != nil && maxDurationReached(parent, ctx)
+	if err != nil && !partial {
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		return err
+	}
+
+	if dl.remux(format) {
+		if err = dl.remuxToMP4(parent, out.Name(), destFile); err != nil {
+			return err
+		}
+	}
+
+	if partial {
+		return &ErrPartialDownload{File: destFile, BytesWritten: written, MaxDuration: dl.MaxDuration}
+	}
+	return nil
+}
+
+// remux reports whether the download of the format has to be remuxed into mp4
+func (dl *Downloader) remux(format *youtube.Format) bool {
+	return dl.RemuxToMP4 && strings.Contains(format.MimeType, "/webm")
+}
+
+// remuxToMP4 copies the streams of a file into an mp4 container
+func (dl *Downloader) remuxToMP4(ctx context.Context, inputFile, outputFile string) error {
+	var stderr bytes.Buffer
+
+	//nolint:gosec
+	ffmpegCmd := exec.CommandContext(ctx, "ffmpeg", "-y",
+		"-i", inputFile,
+		"-c", "copy", // Just copy without re-encoding
+		"-f", "mp4",
+		outputFile,
+		"-loglevel", "error",
+	)
+	ffmpegCmd.Stderr = &stderr
+	dl.logf("remuxing to %s", outputFile)
+
+	if err := ffmpegCmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed to remux into mp4: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
 }
 
 // DownloadComposite : Downloads audio and video streams separately and merges them via ffmpeg.
-func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string, v *youtube.Video, quality string, mimetype string) error {
+// With RemuxToMP4 webm streams are merged into an mp4 file.
+// If MaxDuration is reached, the partial streams are merged as long as both contain data.
+func (dl *Downloader) DownloadComposite(parent context.Context, outputFile string, v *youtube.Video, quality string, mimetype string) error {
+	// the video and audio stream share the retry budget
+	parent = youtube.WithRetryBudget(parent, dl.TotalRetryBudget)
+	ctx, cancel := dl.withMaxDuration(parent)
+	defer cancel()
+
 	videoFormat, audioFormat, err1 := getVideoAudioFormats(v, quality, mimetype)
 	if err1 != nil {
 		return err1
@@ -68,48 +207,246 @@ func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string,
 	if err != nil {
 		return err
 	}
-	outputDir := filepath.Dir(destFile)
-
 	// Create temporary video file
-	videoFile, err := ioutil.TempFile(outputDir, "youtube_*.m4v")
+	videoFile, err := dl.createTemp("youtube_*.m4v")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(videoFile.Name())
+	defer videoFile.Close()
 
 	// Create temporary audio file
-	audioFile, err := ioutil.TempFile(outputDir, "youtube_*.m4a")
+	audioFile, err := dl.createTemp("youtube_*.m4a")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(audioFile.Name())
+	defer audioFile.Close()
 
-	dl.logf("Downloading video file...")
-	err = dl.videoDLWorker(ctx, videoFile, v, videoFormat)
+	// both streams are downloaded at the same time, so both have data when MaxDuration is reached
+	dl.logf("Downloading video and audio file...")
+	videoWritten, audioWritten, err := dl.downloadStreams(parent, ctx, v, videoFile, videoFormat, audioFile, audioFormat)
 	if err != nil {
 		return err
 	}
 
-	dl.logf("Downloading audio file...")
-	err = dl.videoDLWorker(ctx, audioFile, v, audioFormat)
-	if err != nil {
+	var partial *ErrPartialDownload
+	if maxDurationReached(parent, ctx) {
+		partial = &ErrPartialDownload{File: destFile, BytesWritten: videoWritten + audioWritten, MaxDuration: dl.MaxDuration}
+
+		// keep the stream with data as it is, it can't be merged without the other one
+		var single *os.File
+		var singleFormat *youtube.Format
+		switch {
+		case videoWritten == 0 && audioWritten == 0:
+			partial.File = ""
+			return partial
+		case audioWritten == 0:
+			single, singleFormat = videoFile, videoFormat
+		case videoWritten == 0:
+			single, singleFormat = audioFile, audioFormat
+		}
+
+		if single != nil {
+			partial.File = strings.TrimSuffix(destFile, filepath.Ext(destFile)) + pickIdealFileExtension(singleFormat.MimeType)
+			if err = single.Close(); err != nil {
+				return err
+			}
+			if err = moveFile(single.Name(), partial.File); err != nil {
+				return err
+			}
+			return partial
+		}
+	}
+
+	if err = videoFile.Close(); err != nil {
+		return err
+	}
+	if err = audioFile.Close(); err != nil {
 		return err
 	}
 
-	//nolint:gosec
-	ffmpegVersionCmd := exec.Command("ffmpeg", "-y",
+	args := []string{"-y",
 		"-i", videoFile.Name(),
 		"-i", audioFile.Name(),
-		"-c", "copy", // Just copy without re-encoding
+	}
+	args = append(args, muxCodecArgs(destFile, videoFormat, audioFormat)...)
+	args = append(args,
 		"-shortest", // Finish encoding when the shortest input stream ends
 		destFile,
 		"-loglevel", "warning",
 	)
+
+	//nolint:gosec
+	ffmpegVersionCmd := exec.Command("ffmpeg", args...)
 	ffmpegVersionCmd.Stderr = os.Stderr
 	ffmpegVersionCmd.Stdout = os.Stdout
 	dl.logf("merging video and audio to %s", destFile)
 
-	return ffmpegVersionCmd.Run()
+	if err = ffmpegVersionCmd.Run(); err != nil {
+		return err
+	}
+	if partial != nil {
+		return partial
+	}
+	return nil
+}
+
+// downloadStreams downloads the video and audio format at the same time and returns the bytes written
+// of each. Errors other than reaching MaxDuration stop both downloads and are returned.
+func (dl *Downloader) downloadStreams(parent, ctx context.Context, v *youtube.Video,
+	videoFile *os.File, videoFormat *youtube.Format, audioFile *os.File, audioFormat *youtube.Format) (int64, int64, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// both bars share one container, so they don't overwrite each other
+	bars := mpb.New(mpb.WithWidth(64))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	download := func(out *os.File, format *youtube.Format, written *int64) {
+		defer wg.Done()
+
+		var err error
+		*written, err = dl.streamWorker(streamCtx, bars, out, v, format)
+		if err != nil && !maxDurationReached(parent, ctx) {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			mu.Unlock()
+		}
+	}
+
+	var videoWritten, audioWritten int64
+	wg.Add(2)
+	go download(videoFile, videoFormat, &videoWritten)
+	go download(audioFile, audioFormat, &audioWritten)
+	wg.Wait()
+	bars.Wait()
+
+	return videoWritten, audioWritten, firstErr
+}
+
+// moveFile renames a file, or copies it if it's on another file system
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// muxCodecArgs returns the ffmpeg codec arguments to merge the formats into the output file.
+// The streams are copied if the container supports them, otherwise the audio is re-encoded.
+func muxCodecArgs(outputFile string, videoFormat, audioFormat *youtube.Format) []string {
+	var container, audioCodec string
+	switch strings.ToLower(filepath.Ext(outputFile)) {
+	case ".mp4", ".m4v":
+		container, audioCodec = "mp4", "aac"
+	case ".webm":
+		container, audioCodec = "webm", "libopus"
+	default:
+		// e.g. mkv, which supports all codecs
+		return []string{"-c", "copy"}
+	}
+
+	if youtube.CanMuxCopyInto(container, videoFormat, audioFormat) {
+		return []string{"-c", "copy"} // Just copy without re-encoding
+	}
+
+	// re-encoding the audio is cheap, the video is copied in any case
+	return []string{"-c:v", "copy", "-c:a", audioCodec}
+}
+
+// DownloadChannelSince downloads the videos uploaded to a channel on or after the day of since.
+// Uploads are paged newest first and the paging stops at the first video published before that day,
+// so repeated runs don't have to scan the whole channel. Quality and mimetype select the format
+// like the CLI does for muxed streams. Videos which fail are skipped, their errors are returned
+// together as *ErrVideosFailed. The downloaded videos are returned, also on error.
+func (dl *Downloader) DownloadChannelSince(ctx context.Context, channelID string, since time.Time, quality string, mimetype string) ([]*youtube.Video, error) {
+	var videos []*youtube.Video
+	failed := &ErrVideosFailed{}
+
+	// the publish date is only precise to the day, so the time of since doesn't matter
+	sinceDay := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+
+	// all downloads share the retry budget
+	ctx = youtube.WithRetryBudget(ctx, dl.TotalRetryBudget)
+
+	_, err := dl.GetChannelUploadsContext(ctx, channelID, func(entry *youtube.PlaylistEntry) bool {
+		v, err := dl.VideoFromPlaylistEntryContext(ctx, entry)
+		if err != nil {
+			failed.Errors = append(failed.Errors, fmt.Errorf("video %s: %w", entry.ID, err))
+			return ctx.Err() != nil
+		}
+
+		// a zero date is unknown and gets downloaded
+		if !v.PublishDate.IsZero() && v.PublishDate.Before(sinceDay) {
+			return true
+		}
+
+		format, err := getMuxedFormat(v, quality, mimetype)
+		if err != nil {
+			failed.Errors = append(failed.Errors, fmt.Errorf("video %s: %w", v.ID, err))
+			return false
+		}
+
+		if err = dl.Download(ctx, v, format, ""); err != nil {
+			failed.Errors = append(failed.Errors, fmt.Errorf("video %s: %w", v.ID, err))
+			return ctx.Err() != nil
+		}
+
+		videos = append(videos, v)
+		return false
+	})
+	if err != nil {
+		return videos, err
+	}
+
+	if len(failed.Errors) > 0 {
+		return videos, failed
+	}
+	return videos, nil
+}
+
+// getMuxedFormat selects a format containing both video and audio
+func getMuxedFormat(v *youtube.Video, quality string, mimetype string) (*youtube.Format, error) {
+	formats := v.Formats
+	if mimetype != "" {
+		formats = formats.Type(mimetype)
+	}
+	formats = formats.WithAudioChannels()
+
+	if quality != "" {
+		format := formats.FindByQuality(quality)
+		if format == nil {
+			return nil, fmt.Errorf("no format found with quality %s", quality)
+		}
+		return format, nil
+	}
+
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no format found after filtering")
+	}
+
+	formats.Sort()
+	return &formats[0], nil
 }
 
 func getVideoAudioFormats(v *youtube.Video, quality string, mimetype string) (*youtube.Format, *youtube.Format, error) {
@@ -149,19 +486,45 @@ func getVideoAudioFormats(v *youtube.Video, quality string, mimetype string) (*y
 	return videoFormat, audioFormat, nil
 }
 
-func (dl *Downloader) videoDLWorker(ctx context.Context, out *os.File, video *youtube.Video, format *youtube.Format) error {
+// throttleFactor is the multiple of the playback speed below which a download counts as throttled by default
+const throttleFactor = 2
+
+// throttleThreshold returns the speed in bytes per second below which a download of the format is throttled
+func (dl *Downloader) throttleThreshold(format *youtube.Format) float64 {
+	if dl.ThrottleThreshold != 0 {
+		return dl.ThrottleThreshold
+	}
+	return throttleFactor * float64(format.Bitrate) / 8
+}
+
+// videoDLWorker copies the stream into out and returns the number of bytes written
+func (dl *Downloader) videoDLWorker(ctx context.Context, out *os.File, video *youtube.Video, format *youtube.Format) (int64, error) {
+	bars := mpb.New(mpb.WithWidth(64))
+	written, err := dl.streamWorker(ctx, bars, out, video, format)
+	bars.Wait()
+	return written, err
+}
+
+// streamWorker copies the stream into out, showing a bar in bars, and returns the number of bytes written
+func (dl *Downloader) streamWorker(ctx context.Context, bars *mpb.Progress, out *os.File, video *youtube.Video, format *youtube.Format) (int64, error) {
 	stream, size, err := dl.GetStreamContext(ctx, video, format)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	prog := &progress{
-		contentLength: float64(size),
+		contentLength:     float64(size),
+		start:             time.Now(),
+		throttleThreshold: dl.throttleThreshold(format),
+	}
+	if dl.OnProgress != nil {
+		prog.onReport = func(t Throughput) {
+			dl.OnProgress(video, format, t)
+		}
 	}
 
 	// create progress bar
-	progress := mpb.New(mpb.WithWidth(64))
-	bar := progress.AddBar(
+	bar := bars.AddBar(
 		int64(prog.contentLength),
 
 		mpb.PrependDecorators(
@@ -177,13 +540,17 @@ func (dl *Downloader) videoDLWorker(ctx context.Context, out *os.File, video *yo
 
 	reader := bar.ProxyReader(stream)
 	mw := io.MultiWriter(out, prog)
-	_, err = io.Copy(mw, reader)
+	written, err := io.Copy(mw, reader)
 	if err != nil {
-		return err
+		bar.Abort(false)
+		return written, err
 	}
 
-	progress.Wait()
-	return nil
+	if prog.onReport != nil {
+		prog.onReport(prog.throughput(time.Now()))
+	}
+
+	return written, nil
 }
 
 func (dl *Downloader) logf(format string, v ...interface{}) {
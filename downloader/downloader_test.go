@@ -2,8 +2,15 @@ package downloader
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -118,3 +125,169 @@ func Test_getVideoAudioFormats(t *testing.T) {
 		require.Equal(251, audioFormat.ItagNo)
 	}
 }
+
+func TestDownload_MaxDuration(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	dl := testDownloader
+	dl.MaxDuration = 200 * time.Millisecond
+
+	video := &youtube.Video{ID: "max-duration", Title: "max duration"}
+	format := &youtube.Format{ItagNo: 18, URL: server.URL, MimeType: "video/mp4"}
+
+	err := dl.Download(context.Background(), video, format, "max_duration.mp4")
+	var partial *ErrPartialDownload
+	require.ErrorAs(err, &partial)
+	require.EqualValues(7, partial.BytesWritten)
+
+	data, err := os.ReadFile(partial.File)
+	require.NoError(err)
+	require.Equal("partial", string(data))
+}
+
+func TestDownloadComposite_MaxDurationDuringVideo(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the audio stream hasn't sent anything when the deadline expires
+		if r.URL.Path == "/video" {
+			w.Write([]byte("video"))
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	dl := Downloader{OutputDir: testDownloader.OutputDir, MaxDuration: 200 * time.Millisecond}
+
+	video := &youtube.Video{ID: "max-duration-composite", Title: "max duration composite"}
+	videoFormat := &youtube.Format{ItagNo: 137, URL: server.URL + "/video", MimeType: `video/mp4; codecs="avc1.640028"`}
+	audioFormat := &youtube.Format{ItagNo: 140, URL: server.URL + "/audio", MimeType: `audio/mp4; codecs="mp4a.40.2"`, AudioChannels: 2}
+	video.Formats = youtube.FormatList{*videoFormat, *audioFormat}
+
+	err := dl.DownloadComposite(context.Background(), "max_duration_composite.mkv", video, "", "")
+	var partial *ErrPartialDownload
+	require.ErrorAs(err, &partial)
+	require.EqualValues(5, partial.BytesWritten)
+	require.Equal(filepath.Join(testDownloader.OutputDir, "max_duration_composite.mp4"), partial.File)
+
+	data, err := os.ReadFile(partial.File)
+	require.NoError(err)
+	require.Equal("video", string(data))
+}
+
+func Test_muxCodecArgs(t *testing.T) {
+	vp9 := &youtube.Format{MimeType: `video/webm; codecs="vp9"`}
+	opus := &youtube.Format{MimeType: `audio/webm; codecs="opus"`}
+	aac := &youtube.Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`}
+
+	assert.Equal(t, []string{"-c", "copy"}, muxCodecArgs("out.webm", vp9, opus))
+	assert.Equal(t, []string{"-c:v", "copy", "-c:a", "libopus"}, muxCodecArgs("out.webm", vp9, aac))
+	assert.Equal(t, []string{"-c:v", "copy", "-c:a", "aac"}, muxCodecArgs("out.mp4", vp9, opus))
+	assert.Equal(t, []string{"-c", "copy"}, muxCodecArgs("out.mkv", vp9, aac))
+}
+
+func TestDownloader_createTemp(t *testing.T) {
+	var dl Downloader
+	file, err := dl.createTemp("youtube_*.m4v")
+	require.NoError(t, err)
+	file.Close()
+	os.Remove(file.Name())
+	assert.Equal(t, filepath.Clean(os.TempDir()), filepath.Dir(file.Name()))
+
+	dl.TempDir = t.TempDir()
+	file, err = dl.createTemp("youtube_*.m4v")
+	require.NoError(t, err)
+	file.Close()
+	assert.Equal(t, dl.TempDir, filepath.Dir(file.Name()))
+}
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestDownloadChannelSince(t *testing.T) {
+	require := require.New(t)
+
+	// uploads newest first, the publish dates are only days
+	dates := map[string]string{
+		"new00000001": "2024-03-10",
+		"nodate00001": "",
+		"broken00001": "",
+		"today000001": "2024-03-01",
+		"old00000001": "2024-02-28",
+		"older000001": "2024-02-01",
+	}
+	ids := []string{"new00000001", "nodate00001", "broken00001", "today000001", "old00000001", "older000001"}
+
+	var requested []string
+	transport := roundTripFunc(func(req *http.Request) *http.Response {
+		response := func(status int, body string) *http.Response {
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+		}
+
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/embed/"):
+			return response(http.StatusOK, `"jsUrl":"/s/player/abcdef/player_ias.vflset/en_US/base.js"`)
+		case strings.HasPrefix(req.URL.Path, "/s/player/"):
+			return response(http.StatusOK, `var a={b:1,signatureTimestamp:19000}`)
+		case req.URL.Path == "/youtubei/v1/browse":
+			entries := make([]string, len(ids))
+			for i, id := range ids {
+				entries[i] = `{"playlistVideoRenderer": {"videoId": "` + id + `", "lengthSeconds": "60"}}`
+			}
+			return response(http.StatusOK, `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+				{"itemSectionRenderer": {"contents": [{"playlistVideoListRenderer": {"contents": [`+strings.Join(entries, ",")+`]}}]}}
+			]}}}}]}}}`)
+		case req.URL.Path == "/youtubei/v1/player":
+			var request struct {
+				VideoID string `json:"videoId"`
+			}
+			require.NoError(json.NewDecoder(req.Body).Decode(&request))
+			requested = append(requested, request.VideoID)
+			if request.VideoID == "broken00001" {
+				return response(http.StatusInternalServerError, "")
+			}
+			return response(http.StatusOK, fmt.Sprintf(`{"playabilityStatus": {"status": "OK"},
+				"videoDetails": {"videoId": %[1]q, "title": %[1]q},
+				"microformat": {"playerMicroformatRenderer": {"publishDate": %[2]q}},
+				"streamingData": {"formats": [{"itag": 18, "url": "https://stream.test/%[1]s", "mimeType": "video/mp4; codecs=\"avc1.42001E, mp4a.40.2\"", "audioChannels": 2}]}}`,
+				request.VideoID, dates[request.VideoID]))
+		case req.URL.Host == "stream.test":
+			return response(http.StatusOK, "data")
+		}
+		return response(http.StatusNotFound, "")
+	})
+
+	dl := Downloader{OutputDir: t.TempDir()}
+	dl.HTTPClient = &http.Client{Transport: transport}
+
+	// the time of day doesn't exclude videos published on that day
+	since := time.Date(2024, 3, 1, 15, 0, 0, 0, time.Local)
+	videos, err := dl.DownloadChannelSince(context.Background(), "UCaaaaaaaaaaaaaaaaaaaaaa", since, "", "")
+
+	var failed *ErrVideosFailed
+	require.ErrorAs(err, &failed)
+	require.Len(failed.Errors, 1)
+	require.Contains(failed.Errors[0].Error(), "broken00001")
+
+	var downloaded []string
+	for _, v := range videos {
+		downloaded = append(downloaded, v.ID)
+	}
+	require.Equal([]string{"new00000001", "nodate00001", "today000001"}, downloaded)
+	require.NotContains(requested, "older000001", "the paging stops at the first older video")
+
+	data, err := os.ReadFile(filepath.Join(dl.OutputDir, "today000001.mp4"))
+	require.NoError(err)
+	require.Equal("data", string(data))
+}
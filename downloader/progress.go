@@ -1,9 +1,33 @@
 package downloader
 
+import (
+	"time"
+)
+
+// throttleWarmup is the time a download needs to run before its speed is judged
+const throttleWarmup = 5 * time.Second
+
+// Throughput describes the observed speed of a running download
+type Throughput struct {
+	BytesWritten   int64
+	Elapsed        time.Duration
+	BytesPerSecond float64
+
+	// ThrottleDetected is a heuristic signal set when the download is slower than the threshold
+	// after a short warm-up. Youtube throttles downloads to about the playback speed, so the default
+	// threshold of Downloader.ThrottleThreshold leaves a margin above it.
+	ThrottleDetected bool
+}
+
 type progress struct {
 	contentLength     float64
 	totalWrittenBytes float64
 	downloadLevel     float64
+
+	start             time.Time
+	throttleThreshold float64 // bytes per second
+	lastReport        time.Time
+	onReport          func(Throughput)
 }
 
 func (dl *progress) Write(p []byte) (n int, err error) {
@@ -13,5 +37,27 @@ func (dl *progress) Write(p []byte) (n int, err error) {
 	if (dl.downloadLevel <= currentPercent) && (dl.downloadLevel < 100) {
 		dl.downloadLevel++
 	}
+
+	if dl.onReport != nil {
+		if now := time.Now(); now.Sub(dl.lastReport) >= time.Second {
+			dl.lastReport = now
+			dl.onReport(dl.throughput(now))
+		}
+	}
 	return
 }
+
+// throughput returns the speed observed until now
+func (dl *progress) throughput(now time.Time) Throughput {
+	t := Throughput{
+		BytesWritten: int64(dl.totalWrittenBytes),
+		Elapsed:      now.Sub(dl.start),
+	}
+
+	if t.Elapsed > 0 {
+		t.BytesPerSecond = dl.totalWrittenBytes / t.Elapsed.Seconds()
+	}
+
+	t.ThrottleDetected = dl.throttleThreshold > 0 && t.Elapsed >= throttleWarmup && t.BytesPerSecond < dl.throttleThreshold
+	return t
+}
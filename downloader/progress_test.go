@@ -0,0 +1,48 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_throughput(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	prog := &progress{
+		contentLength:     1000,
+		start:             start,
+		throttleThreshold: 100,
+	}
+
+	_, _ = prog.Write(make([]byte, 200))
+
+	// too early to judge
+	tp := prog.throughput(start.Add(time.Second))
+	assert.EqualValues(t, 200, tp.BytesWritten)
+	assert.Equal(t, 200.0, tp.BytesPerSecond)
+	assert.False(t, tp.ThrottleDetected)
+
+	tp = prog.throughput(start.Add(4 * time.Second))
+	assert.Equal(t, 50.0, tp.BytesPerSecond)
+	assert.False(t, tp.ThrottleDetected)
+
+	tp = prog.throughput(start.Add(10 * time.Second))
+	assert.Equal(t, 20.0, tp.BytesPerSecond)
+	assert.True(t, tp.ThrottleDetected)
+
+	// no threshold, no detection
+	prog.throttleThreshold = 0
+	assert.False(t, prog.throughput(start.Add(10*time.Second)).ThrottleDetected)
+}
+
+func TestDownloader_throttleThreshold(t *testing.T) {
+	format := &youtube.Format{Bitrate: 800_000}
+
+	var dl Downloader
+	assert.Equal(t, 200_000.0, dl.throttleThreshold(format))
+
+	dl.ThrottleThreshold = 50_000
+	assert.Equal(t, 50_000.0, dl.throttleThreshold(format))
+}
@@ -2,6 +2,7 @@ package youtube
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
@@ -14,6 +15,13 @@ const (
 	ErrLoginRequired              = constError("login required to confirm your age")
 	ErrVideoPrivate               = constError("user restricted access to this video")
 	ErrInvalidPlaylist            = constError("no playlist detected or invalid playlist ID")
+	ErrInvalidChannel             = constError("no channel detected or invalid channel ID")
+	ErrNoFormats                  = constError("no formats found in the server's answer")
+	ErrFormatNotInVideo           = constError("the format doesn't belong to the video")
+	ErrUnsupportedURL             = constError("the URL doesn't refer to a supported YouTube resource")
+	ErrRetryBudgetExhausted       = constError("the retry budget is exhausted")
+	ErrNotAuthenticated           = constError("not signed in, the cookies of a YouTube session are required")
+	ErrUnknownTrendingCategory    = constError("unknown trending category")
 )
 
 type constError string
@@ -31,6 +39,30 @@ func (err ErrPlayabiltyStatus) Error() string {
 	return fmt.Sprintf("cannot playback and download, status: %s, reason: %s", err.Status, err.Reason)
 }
 
+// ErrBotCheck is returned when YouTube asks to sign in to confirm not being a bot.
+// Setting Client.VisitorData and Client.POToken may unblock the request,
+// the Missing fields tell which of them weren't set.
+type ErrBotCheck struct {
+	Reason             string
+	MissingVisitorData bool
+	MissingPOToken     bool
+}
+
+func (err ErrBotCheck) Error() string {
+	var missing []string
+	if err.MissingVisitorData {
+		missing = append(missing, "Client.VisitorData")
+	}
+	if err.MissingPOToken {
+		missing = append(missing, "Client.POToken")
+	}
+
+	if len(missing) == 0 {
+		return fmt.Sprintf("bot check failed, reason: %s, the configured visitor data or PO token may be invalid or expired", err.Reason)
+	}
+	return fmt.Sprintf("bot check failed, reason: %s, set %s to unblock the request", err.Reason, strings.Join(missing, " and "))
+}
+
 // ErrUnexpectedStatusCode is returned on unexpected HTTP status codes
 type ErrUnexpectedStatusCode int
 
@@ -38,6 +70,22 @@ func (err ErrUnexpectedStatusCode) Error() string {
 	return fmt.Sprintf("unexpected status code: %d", err)
 }
 
+// ErrChunkFailed is returned by streams when a chunk still fails after Client.ChunkRetries
+type ErrChunkFailed struct {
+	Start    int64 // first byte which couldn't be loaded
+	End      int64 // last byte of the chunk
+	Attempts int
+	Err      error
+}
+
+func (err ErrChunkFailed) Error() string {
+	return fmt.Sprintf("chunk bytes=%d-%d failed after %d attempts: %v", err.Start, err.End, err.Attempts, err.Err)
+}
+
+func (err ErrChunkFailed) Unwrap() error {
+	return err.Err
+}
+
 type ErrPlaylistStatus struct {
 	Reason string
 }
@@ -45,3 +93,11 @@ type ErrPlaylistStatus struct {
 func (err ErrPlaylistStatus) Error() string {
 	return fmt.Sprintf("could not load playlist: %s", err.Reason)
 }
+
+type ErrChannelStatus struct {
+	Reason string
+}
+
+func (err ErrChannelStatus) Error() string {
+	return fmt.Sprintf("could not load channel: %s", err.Reason)
+}
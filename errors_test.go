@@ -15,6 +15,10 @@ func TestErrors(t *testing.T) {
 		{ErrUnexpectedStatusCode(404), "unexpected status code: 404"},
 		{ErrPlayabiltyStatus{"invalid", "for that reason"}, "cannot playback and download, status: invalid, reason: for that reason"},
 		{ErrPlaylistStatus{"for that reason"}, "could not load playlist: for that reason"},
+		{ErrChannelStatus{"for that reason"}, "could not load channel: for that reason"},
+		{ErrBotCheck{"for that reason", true, true}, "bot check failed, reason: for that reason, set Client.VisitorData and Client.POToken to unblock the request"},
+		{ErrBotCheck{"for that reason", false, true}, "bot check failed, reason: for that reason, set Client.POToken to unblock the request"},
+		{ErrBotCheck{"for that reason", false, false}, "bot check failed, reason: for that reason, the configured visitor data or PO token may be invalid or expired"},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
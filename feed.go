@@ -0,0 +1,400 @@
+package youtube
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	sjson "github.com/bitly/go-simplejson"
+)
+
+// youtubeOrigin is the origin of authenticated innertube requests
+const youtubeOrigin = "https://www.youtube.com"
+
+// GetSubscriptionsFeed fetches the recent videos of the channels the user is subscribed to
+func (c *Client) GetSubscriptionsFeed() ([]*PlaylistEntry, error) {
+	return c.GetSubscriptionsFeedContext(context.Background())
+}
+
+// GetSubscriptionsFeedContext fetches the recent videos of the channels the user is subscribed to
+// with a context. The request is authenticated with the cookies of a signed-in browser session,
+// which have to be in the cookie jar of the HTTPClient. ErrNotAuthenticated is returned without them.
+// All pages of the feed are fetched, use GetSubscriptionsFeedUntilContext to stop earlier.
+// Only the data of the feed is returned, use VideoFromPlaylistEntry to get the full metadata of a video.
+func (c *Client) GetSubscriptionsFeedContext(ctx context.Context) ([]*PlaylistEntry, error) {
+	return c.GetSubscriptionsFeedUntilContext(ctx, nil)
+}
+
+// GetSubscriptionsFeedUntil fetches the recent videos of the subscriptions until stop returns true
+func (c *Client) GetSubscriptionsFeedUntil(stop func(*PlaylistEntry) bool) ([]*PlaylistEntry, error) {
+	return c.GetSubscriptionsFeedUntilContext(context.Background(), stop)
+}
+
+// GetSubscriptionsFeedUntilContext fetches the feed like GetSubscriptionsFeedContext, but only until
+// the stop function returns true for an entry. The stopping entry is still part of the result.
+// A nil stop function fetches all pages.
+func (c *Client) GetSubscriptionsFeedUntilContext(ctx context.Context, stop func(*PlaylistEntry) bool) ([]*PlaylistEntry, error) {
+	header, err := c.authorizationHeader(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	data := innertubeRequest{
+		Context:  c.prepareInnertubeContext(webClient),
+		BrowseID: "FEsubscriptions",
+	}
+
+	var entries []*PlaylistEntry
+	err = c.browseFeed(ctx, data, header, parseSubscriptionsFeed, func(entry *PlaylistEntry) bool {
+		entries = append(entries, entry)
+		return stop != nil && stop(entry)
+	})
+	return entries, err
+}
+
+// browseFeed calls visit for the entries of a browse page and its continuations until visit returns true.
+// The continuations are requested with the context of data. The parse function returns the entries
+// of a page and the continuation token of the next one.
+func (c *Client) browseFeed(ctx context.Context, data innertubeRequest, header http.Header,
+	parse func([]byte) ([]*PlaylistEntry, string, error), visit func(*PlaylistEntry) bool) error {
+	browseID := data.BrowseID
+	for {
+		resp, err := c.httpPostWithHeader(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+webClient.key, data, header)
+		if err != nil {
+			return err
+		}
+
+		body, err := readBody(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		entries, continuation, err := parse(body)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if visit(entry) {
+				return nil
+			}
+		}
+
+		// the same token again would never end
+		if continuation == "" || continuation == data.Continuation {
+			return nil
+		}
+		c.logf("%s continuation: %s", browseID, continuation)
+
+		data = innertubeRequest{
+			Context:      data.Context,
+			Continuation: continuation,
+		}
+	}
+}
+
+// Trending categories for GetTrending
+const (
+	TrendingNow    = "now"
+	TrendingMusic  = "music"
+	TrendingGaming = "gaming"
+	TrendingMovies = "movies"
+)
+
+// trendingParams are the browse params selecting the tab of a trending category
+var trendingParams = map[string]string{
+	TrendingNow:    "",
+	TrendingMusic:  "4gINGgt5dG1hX2NoYXJ0cw==",
+	TrendingGaming: "4gIcGhpnYW1pbmdfY29ycHVzX21vc3RfcG9wdWxhcg==",
+	TrendingMovies: "4gIKGgh0cmFpbGVycw==",
+}
+
+// GetTrending fetches the trending videos of a region and category
+func (c *Client) GetTrending(region, category string) ([]*PlaylistEntry, error) {
+	return c.GetTrendingContext(context.Background(), region, category)
+}
+
+// GetTrendingContext fetches the trending videos of a region and category with a context.
+// The region is a country code like "DE", Client.Region is used if it's empty.
+// The category is one of the Trending constants, an empty category is TrendingNow.
+// All shelves are returned, following the continuations of the page if there are any.
+// Only the data of the page is returned, not full Videos, as these would cost a request each.
+// Use VideoFromPlaylistEntry to get the full metadata of a video.
+func (c *Client) GetTrendingContext(ctx context.Context, region, category string) ([]*PlaylistEntry, error) {
+	if category == "" {
+		category = TrendingNow
+	}
+	params, ok := trendingParams[strings.ToLower(category)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTrendingCategory, category)
+	}
+
+	data := innertubeRequest{
+		Context:  c.prepareInnertubeContext(webClient),
+		BrowseID: "FEtrending",
+		Params:   params,
+	}
+	if region != "" {
+		data.Context.Client.GL = strings.ToUpper(region)
+	}
+
+	var entries []*PlaylistEntry
+	err := c.browseFeed(ctx, data, nil, parseTrending, func(entry *PlaylistEntry) bool {
+		entries = append(entries, entry)
+		return false
+	})
+	return entries, err
+}
+
+// parseTrending returns the videos of the shelves of a trending page and the token of the next page
+func parseTrending(body []byte) (entries []*PlaylistEntry, continuation string, err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	for _, key := range []string{"contents", "onResponseReceivedActions"} {
+		items := j.Get(key).Interface()
+
+		page, err := parseFeedEntries(items)
+		if err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, page...)
+
+		if continuation, err = feedContinuation(items, continuation); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return entries, continuation, nil
+}
+
+// shortsFeedBrowseIDs are the browse pages with shorts shelves, trending and the home feed
+var shortsFeedBrowseIDs = []string{"FEtrending", "FEwhat_to_watch"}
+
+// GetShortsFeed fetches the shorts of the trending page and the home feed
+func (c *Client) GetShortsFeed(stop func(*PlaylistEntry) bool) ([]*PlaylistEntry, error) {
+	return c.GetShortsFeedContext(context.Background(), stop)
+}
+
+// GetShortsFeedContext fetches the shorts of the trending page and the home feed with a context.
+// The pages of the feeds are fetched until they end or the stop function returns true for an entry,
+// the stopping entry is still part of the result. A nil stop function fetches all pages.
+// Shorts appearing on several pages are only returned once.
+func (c *Client) GetShortsFeedContext(ctx context.Context, stop func(*PlaylistEntry) bool) ([]*PlaylistEntry, error) {
+	var entries []*PlaylistEntry
+	seen := map[string]bool{}
+	stopped := false
+
+	for _, browseID := range shortsFeedBrowseIDs {
+		data := innertubeRequest{
+			Context:  c.prepareInnertubeContext(webClient),
+			BrowseID: browseID,
+		}
+		err := c.browseFeed(ctx, data, nil, parseShortsFeed, func(entry *PlaylistEntry) bool {
+			if seen[entry.ID] {
+				return false
+			}
+			seen[entry.ID] = true
+
+			entries = append(entries, entry)
+			stopped = stop != nil && stop(entry)
+			return stopped
+		})
+		if err != nil || stopped {
+			return entries, err
+		}
+	}
+
+	return entries, nil
+}
+
+// parseShortsFeed returns the shorts of a browse page or continuation and the token of the next page.
+// Shorts: reelItemRenderer or shortsLockupViewModel anywhere in contents or onResponseReceivedActions
+func parseShortsFeed(body []byte) (entries []*PlaylistEntry, continuation string, err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	for _, key := range []string{"contents", "onResponseReceivedActions"} {
+		items := j.Get(key).Interface()
+
+		clips, err := parseReelItems(items)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, clip := range clips {
+			if clip.ID == "" {
+				continue
+			}
+			entries = append(entries, &PlaylistEntry{
+				ID:         clip.ID,
+				Title:      clip.Title,
+				Thumbnails: clip.Thumbnails,
+			})
+		}
+
+		if continuation, err = feedContinuation(items, continuation); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return entries, continuation, nil
+}
+
+// authorizationHeader returns the headers authenticating a request by the SAPISID cookie
+func (c *Client) authorizationHeader(now time.Time) (http.Header, error) {
+	if c.HTTPClient == nil || c.HTTPClient.Jar == nil {
+		return nil, ErrNotAuthenticated
+	}
+
+	origin, _ := url.Parse(youtubeOrigin)
+	var sapisid string
+	for _, cookie := range c.HTTPClient.Jar.Cookies(origin) {
+		if cookie.Name == "SAPISID" || (sapisid == "" && cookie.Name == "__Secure-3PAPISID") {
+			sapisid = cookie.Value
+		}
+	}
+	if sapisid == "" {
+		return nil, ErrNotAuthenticated
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	hash := sha1.Sum([]byte(timestamp + " " + sapisid + " " + youtubeOrigin)) //nolint:gosec
+
+	return http.Header{
+		"Authorization":   {"SAPISIDHASH " + timestamp + "_" + hex.EncodeToString(hash[:])},
+		"X-Origin":        {youtubeOrigin},
+		"X-Goog-Authuser": {"0"},
+	}, nil
+}
+
+// parseSubscriptionsFeed returns the videos of a page of the feed and the token of the next page.
+// Signed out sessions get ErrNotAuthenticated.
+func parseSubscriptionsFeed(body []byte) (entries []*PlaylistEntry, continuation string, err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	if j.GetPath("responseContext", "mainAppWebResponseContext", "loggedOut").MustBool() {
+		return nil, "", ErrNotAuthenticated
+	}
+
+	for _, key := range []string{"contents", "onResponseReceivedActions"} {
+		items := j.Get(key).Interface()
+
+		page, err := parseFeedEntries(items)
+		if err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, page...)
+
+		if continuation, err = feedContinuation(items, continuation); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return entries, continuation, nil
+}
+
+// feedContinuation returns the continuation token of a page, or the given one if there is none.
+// The token of the page follows its items, so the last one is kept.
+func feedContinuation(items interface{}, continuation string) (string, error) {
+	for _, data := range findRenderers(items, "continuationItemRenderer") {
+		var item continuationItem
+		if err := remarshal(data, &item); err != nil {
+			return "", err
+		}
+		if token := item.ContinuationEndpoint.ContinuationCommand.Token; token != "" {
+			continuation = token
+		}
+	}
+	return continuation, nil
+}
+
+// parseFeedEntries returns the videos of the video renderers in a browse response, in the order of the page
+func parseFeedEntries(contents interface{}) ([]*PlaylistEntry, error) {
+	var entries []*PlaylistEntry
+	for _, r := range findAnyRenderers(contents, "videoRenderer", "gridVideoRenderer") {
+		var video feedVideoRenderer
+		if err := remarshal(r.Data, &video); err != nil {
+			return nil, err
+		}
+		entries = append(entries, video.PlaylistEntry())
+	}
+	return entries, nil
+}
+
+type feedVideoRenderer struct {
+	ID         string     `json:"videoId"`
+	Title      simpleText `json:"title"`
+	Owner      simpleText `json:"ownerText"`
+	Byline     simpleText `json:"shortBylineText"`
+	LengthText simpleText `json:"lengthText"`
+	Thumbnail  struct {
+		Thumbnails Thumbnails `json:"thumbnails"`
+	} `json:"thumbnail"`
+}
+
+func (r feedVideoRenderer) PlaylistEntry() *PlaylistEntry {
+	author := r.Owner.String()
+	if author == "" {
+		author = r.Byline.String()
+	}
+
+	return &PlaylistEntry{
+		ID:         r.ID,
+		Title:      r.Title.String(),
+		Author:     author,
+		Duration:   parseClockDuration(r.LengthText.String()),
+		Thumbnails: r.Thumbnail.Thumbnails,
+	}
+}
+
+// parseClockDuration parses durations like "1:02:03" or "4:05", zero if invalid
+func parseClockDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+
+	var d time.Duration
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		d = d*60 + time.Duration(n)
+	}
+	return d * time.Second
+}
@@ -0,0 +1,249 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_authorizationHeader(t *testing.T) {
+	_, err := (&Client{}).authorizationHeader(time.Now())
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &Client{HTTPClient: &http.Client{Jar: jar}}
+
+	_, err = client.authorizationHeader(time.Now())
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+
+	origin, _ := url.Parse(youtubeOrigin)
+	jar.SetCookies(origin, []*http.Cookie{{Name: "SAPISID", Value: "sapisid-value"}})
+
+	header, err := client.authorizationHeader(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "SAPISIDHASH 1700000000_ade2239a1ec948ec8b27fe884ba5c1cdc9388057", header.Get("Authorization"))
+	assert.Equal(t, youtubeOrigin, header.Get("X-Origin"))
+}
+
+func TestClient_GetSubscriptionsFeedContext_NotAuthenticated(t *testing.T) {
+	_, err := (&Client{}).GetSubscriptionsFeedContext(context.Background())
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+}
+
+func Test_parseSubscriptionsFeed(t *testing.T) {
+	entries, continuation, err := parseSubscriptionsFeed([]byte(`{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+		{"richItemRenderer": {"content": {"videoRenderer": {
+			"videoId": "rFejpH_tAHM",
+			"title": {"runs": [{"text": "dotGo 2015 - Rob Pike - Simplicity is Complicated"}]},
+			"ownerText": {"runs": [{"text": "dotconferences"}]},
+			"lengthText": {"simpleText": "23:06"},
+			"thumbnail": {"thumbnails": [{"url": "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault.jpg", "width": 480, "height": 270}]}
+		}}}},
+		{"richItemRenderer": {"content": {"videoRenderer": {
+			"videoId": "cQ7STILAS0M",
+			"title": {"runs": [{"text": "Livestream"}]},
+			"shortBylineText": {"runs": [{"text": "Go"}]},
+			"lengthText": {"simpleText": "1:02:03"}
+		}}}},
+		{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "next"}}}}
+	]}}}}]}}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*PlaylistEntry{
+		{
+			ID:         "rFejpH_tAHM",
+			Title:      "dotGo 2015 - Rob Pike - Simplicity is Complicated",
+			Author:     "dotconferences",
+			Duration:   23*time.Minute + 6*time.Second,
+			Thumbnails: Thumbnails{{URL: "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault.jpg", Width: 480, Height: 270}},
+		},
+		{ID: "cQ7STILAS0M", Title: "Livestream", Author: "Go", Duration: time.Hour + 2*time.Minute + 3*time.Second},
+	}, entries)
+	assert.Equal(t, "next", continuation)
+
+	_, _, err = parseSubscriptionsFeed([]byte(`{"responseContext": {"mainAppWebResponseContext": {"loggedOut": true}}}`))
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+}
+
+func TestClient_GetSubscriptionsFeedContext(t *testing.T) {
+	responses := map[string]string{
+		"FEsubscriptions": `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+			{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "aaaaaaaaaaa"}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page2"}}}}
+		]}}}}]}}}`,
+		"page2": `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "bbbbbbbbbbb"}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page3"}}}}
+		]}}]}`,
+		"page3": `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "ccccccccccc"}}}}
+		]}}]}`,
+	}
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	origin, _ := url.Parse(youtubeOrigin)
+	jar.SetCookies(origin, []*http.Cookie{{Name: "SAPISID", Value: "sapisid"}})
+
+	var requests int
+	client := &Client{HTTPClient: &http.Client{Jar: jar, Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		requests++
+		assert.Contains(t, req.Header.Get("Authorization"), "SAPISIDHASH ")
+
+		var request innertubeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(responses[request.BrowseID+request.Continuation])),
+		}
+	})}}
+
+	entries, err := client.GetSubscriptionsFeedContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*PlaylistEntry{{ID: "aaaaaaaaaaa"}, {ID: "bbbbbbbbbbb"}, {ID: "ccccccccccc"}}, entries)
+	assert.Equal(t, 3, requests)
+
+	requests = 0
+	entries, err = client.GetSubscriptionsFeedUntilContext(context.Background(), func(entry *PlaylistEntry) bool {
+		return entry.ID == "aaaaaaaaaaa"
+	})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, requests)
+}
+
+func TestClient_GetShortsFeedContext(t *testing.T) {
+	responses := map[string]string{
+		"FEtrending": `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+			{"itemSectionRenderer": {"contents": [{"videoRenderer": {"videoId": "cQ7STILAS0M"}}]}},
+			{"itemSectionRenderer": {"contents": [{"reelShelfRenderer": {"items": [
+				{"reelItemRenderer": {"videoId": "aaaaaaaaaaa", "headline": {"simpleText": "Trending short"}}}
+			]}}]}}
+		]}}}}]}}}`,
+		"FEwhat_to_watch": `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+			{"richSectionRenderer": {"content": {"richShelfRenderer": {"contents": [
+				{"richItemRenderer": {"content": {"shortsLockupViewModel": {"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "bbbbbbbbbbb"}}}, "overlayMetadata": {"primaryText": {"content": "Home short"}}}}}},
+				{"richItemRenderer": {"content": {"reelItemRenderer": {"videoId": "aaaaaaaaaaa", "headline": {"simpleText": "Trending short"}}}}}
+			]}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page2"}}}}
+		]}}}}]}}}`,
+		"page2": `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"richSectionRenderer": {"content": {"richShelfRenderer": {"contents": [
+				{"richItemRenderer": {"content": {"shortsLockupViewModel": {"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "ccccccccccc"}}}, "overlayMetadata": {"primaryText": {"content": "Next page"}}}}}}
+			]}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page2"}}}}
+		]}}]}`,
+	}
+
+	client := &Client{HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var request innertubeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(responses[request.BrowseID+request.Continuation])),
+		}
+	})}}
+
+	entries, err := client.GetShortsFeedContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*PlaylistEntry{
+		{ID: "aaaaaaaaaaa", Title: "Trending short"},
+		{ID: "bbbbbbbbbbb", Title: "Home short"},
+		{ID: "ccccccccccc", Title: "Next page"},
+	}, entries)
+
+	entries, err = client.GetShortsFeedContext(context.Background(), func(entry *PlaylistEntry) bool {
+		return entry.ID == "bbbbbbbbbbb"
+	})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestClient_GetTrendingContext(t *testing.T) {
+	responses := map[string]string{
+		"FEtrending": `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+			{"itemSectionRenderer": {"contents": [{"shelfRenderer": {"content": {"expandedShelfContentsRenderer": {"items": [
+				{"videoRenderer": {"videoId": "rFejpH_tAHM", "title": {"runs": [{"text": "Trending"}]}, "ownerText": {"runs": [{"text": "dotconferences"}]}, "lengthText": {"simpleText": "22:51"}}}
+			]}}}}]}},
+			{"itemSectionRenderer": {"contents": [{"reelShelfRenderer": {"items": [{"reelItemRenderer": {"videoId": "aaaaaaaaaaa"}}]}}]}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page2"}}}}
+		]}}}}]}}}`,
+		"page2": `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"itemSectionRenderer": {"contents": [{"shelfRenderer": {"content": {"expandedShelfContentsRenderer": {"items": [
+				{"videoRenderer": {"videoId": "bbbbbbbbbbb", "title": {"runs": [{"text": "Next page"}]}}}
+			]}}}}]}}
+		]}}]}`,
+	}
+
+	var requests []innertubeRequest
+	client := &Client{Region: "DE", HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var request innertubeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		requests = append(requests, request)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(responses[request.BrowseID+request.Continuation])),
+		}
+	})}}
+
+	entries, err := client.GetTrendingContext(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []*PlaylistEntry{
+		{ID: "rFejpH_tAHM", Title: "Trending", Author: "dotconferences", Duration: 22*time.Minute + 51*time.Second},
+		{ID: "bbbbbbbbbbb", Title: "Next page"},
+	}, entries)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "FEtrending", requests[0].BrowseID)
+	assert.Equal(t, "DE", requests[0].Context.Client.GL)
+	assert.Empty(t, requests[0].Params)
+	assert.Equal(t, "page2", requests[1].Continuation)
+	assert.Equal(t, "DE", requests[1].Context.Client.GL, "the continuation keeps the region")
+
+	requests = nil
+	_, err = client.GetTrendingContext(context.Background(), "gb", TrendingMusic)
+	require.NoError(t, err)
+	assert.Equal(t, "GB", requests[0].Context.Client.GL)
+	assert.Equal(t, trendingParams[TrendingMusic], requests[0].Params)
+
+	_, err = client.GetTrendingContext(context.Background(), "", "sports")
+	assert.ErrorIs(t, err, ErrUnknownTrendingCategory)
+}
+
+func Test_parseFeedEntries_Order(t *testing.T) {
+	var contents interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"shelfRenderer": {"content": {"gridRenderer": {"items": [{"gridVideoRenderer": {"videoId": "aaaaaaaaaaa"}}]}}}},
+		{"shelfRenderer": {"content": {"expandedShelfContentsRenderer": {"items": [{"videoRenderer": {"videoId": "bbbbbbbbbbb"}}]}}}},
+		{"shelfRenderer": {"content": {"gridRenderer": {"items": [{"gridVideoRenderer": {"videoId": "ccccccccccc"}}]}}}}
+	]`), &contents))
+
+	entries, err := parseFeedEntries(contents)
+	require.NoError(t, err)
+	assert.Equal(t, []*PlaylistEntry{{ID: "aaaaaaaaaaa"}, {ID: "bbbbbbbbbbb"}, {ID: "ccccccccccc"}}, entries)
+}
+
+func Test_parseReelItems_Order(t *testing.T) {
+	var contents interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"shortsLockupViewModel": {"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "aaaaaaaaaaa"}}}}},
+		{"reelItemRenderer": {"videoId": "bbbbbbbbbbb"}},
+		{"shortsLockupViewModel": {"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "ccccccccccc"}}}}}
+	]`), &contents))
+
+	clips, err := parseReelItems(contents)
+	require.NoError(t, err)
+	assert.Equal(t, []DerivedClip{{ID: "aaaaaaaaaaa"}, {ID: "bbbbbbbbbbb"}, {ID: "ccccccccccc"}}, clips)
+}
@@ -70,12 +70,74 @@ func (list FormatList) WithAudioChannels() (result FormatList) {
 	return result
 }
 
+// Height returns a new FormatList filtered by the exact video height
+func (list FormatList) Height(h int) (result FormatList) {
+	for _, f := range list {
+		if f.Height == h {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 // FilterQuality reduces the format list to formats matching the quality
 func (v *Video) FilterQuality(quality string) {
 	v.Formats = v.Formats.Quality(quality)
 	v.Formats.Sort()
 }
 
+// contains reports whether the format is part of the list
+func (list FormatList) contains(format *Format) bool {
+	for i := range list {
+		if &list[i] == format ||
+			(list[i].ItagNo == format.ItagNo && list[i].URL == format.URL && list[i].Cipher == format.Cipher) {
+			return true
+		}
+	}
+	return false
+}
+
+// rankQuality sets the QualityRank of the formats
+func (list FormatList) rankQuality() {
+	var video, audio []*Format
+	for i := range list {
+		if list[i].Height > 0 {
+			video = append(video, &list[i])
+		} else {
+			audio = append(audio, &list[i])
+		}
+	}
+
+	rank := func(formats []*Format, less func(a, b *Format) bool) {
+		sort.SliceStable(formats, func(i, j int) bool {
+			return less(formats[i], formats[j])
+		})
+
+		for i, format := range formats {
+			// equal formats get the same rank
+			last := i
+			for last+1 < len(formats) && !less(format, formats[last+1]) {
+				last++
+			}
+
+			format.QualityRank = 1
+			if len(formats) > 1 {
+				format.QualityRank = float64(last) / float64(len(formats)-1)
+			}
+		}
+	}
+
+	rank(video, func(a, b *Format) bool {
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		return a.Bitrate < b.Bitrate
+	})
+	rank(audio, func(a, b *Format) bool {
+		return a.Bitrate < b.Bitrate
+	})
+}
+
 // Sort sorts all formats fields
 func (list FormatList) Sort() {
 	sort.SliceStable(list, func(i, j int) bool {
@@ -204,3 +204,51 @@ func TestFormatList_WithAudioChannels(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatList_Height(t *testing.T) {
+	list := FormatList{
+		{ItagNo: 137, Height: 1080, QualityLabel: "1080p"},
+		{ItagNo: 299, Height: 1080, QualityLabel: "1080p60"},
+		{ItagNo: 136, Height: 720, QualityLabel: "720p"},
+		{ItagNo: 140},
+	}
+
+	assert.Equal(t, FormatList{list[0], list[1]}, list.Height(1080))
+	assert.Equal(t, FormatList{list[2]}, list.Height(720))
+	assert.Empty(t, list.Height(480))
+
+	video := &Video{Formats: list}
+	assert.Equal(t, FormatList{list[2]}, video.FormatsByHeight(720))
+}
+
+func TestFormatList_rankQuality(t *testing.T) {
+	list := FormatList{
+		{ItagNo: 137, Height: 1080, Bitrate: 4000000},
+		{ItagNo: 18, Height: 360, Bitrate: 500000},
+		{ItagNo: 22, Height: 720, Bitrate: 2000000},
+		{ItagNo: 136, Height: 720, Bitrate: 2000000},
+		{ItagNo: 251, Bitrate: 160000},
+		{ItagNo: 140, Bitrate: 130000},
+	}
+	list.rankQuality()
+
+	ranks := map[int]float64{}
+	for _, format := range list {
+		ranks[format.ItagNo] = format.QualityRank
+	}
+	assert.Equal(t, map[int]float64{
+		137: 1,
+		22:  2.0 / 3,
+		136: 2.0 / 3,
+		18:  0,
+		251: 1,
+		140: 0,
+	}, ranks)
+
+	// the order of the list is kept
+	assert.Equal(t, 137, list[0].ItagNo)
+
+	single := FormatList{{ItagNo: 18, Height: 360}}
+	single.rankQuality()
+	assert.Equal(t, 1.0, single[0].QualityRank)
+}
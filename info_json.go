@@ -0,0 +1,190 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// infoJSON is the subset of the yt-dlp info JSON written by MarshalInfoJSON
+type infoJSON struct {
+	Type         string `json:"_type"`
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	FullTitle    string `json:"fulltitle"`
+	Description  string `json:"description"`
+	Uploader     string `json:"uploader"`
+	Duration     int    `json:"duration"`
+	UploadDate   string `json:"upload_date,omitempty"`
+	WebpageURL   string `json:"webpage_url"`
+	Extractor    string `json:"extractor"`
+	ExtractorKey string `json:"extractor_key"`
+
+	Thumbnail  string          `json:"thumbnail,omitempty"`
+	Thumbnails []infoThumbnail `json:"thumbnails"`
+	Chapters   []infoChapter   `json:"chapters,omitempty"`
+	Formats    []infoFormat    `json:"formats"`
+
+	// the selected format, or the combination of the requested formats
+	*infoFormat
+	RequestedFormats []infoFormat `json:"requested_formats,omitempty"`
+}
+
+type infoThumbnail struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Width  uint   `json:"width,omitempty"`
+	Height uint   `json:"height,omitempty"`
+}
+
+type infoChapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
+type infoFormat struct {
+	FormatID      string  `json:"format_id"`
+	FormatNote    string  `json:"format_note,omitempty"`
+	URL           string  `json:"url,omitempty"`
+	Ext           string  `json:"ext"`
+	VCodec        string  `json:"vcodec"`
+	ACodec        string  `json:"acodec"`
+	Width         int     `json:"width,omitempty"`
+	Height        int     `json:"height,omitempty"`
+	FPS           int     `json:"fps,omitempty"`
+	TBR           float64 `json:"tbr,omitempty"` // kbit/s
+	Filesize      int64   `json:"filesize,omitempty"`
+	ASR           int     `json:"asr,omitempty"`
+	AudioChannels int     `json:"audio_channels,omitempty"`
+}
+
+// MarshalInfoJSON encodes the video in a subset of the info JSON of yt-dlp, for tools consuming it.
+// The selected formats become the format of the info, a video and an audio format are combined
+// like "137+140". No requests are made, so only formats with a plain stream URL get a URL, with the
+// POToken added. Formats which need deciphering have none, use GetStreamURL to resolve them.
+func (c *Client) MarshalInfoJSON(v *Video, selected ...*Format) ([]byte, error) {
+	info := infoJSON{
+		Type:         "video",
+		ID:           v.ID,
+		Title:        v.Title,
+		FullTitle:    v.Title,
+		Description:  v.Description,
+		Uploader:     v.Author,
+		Duration:     int(v.Duration.Seconds()),
+		WebpageURL:   v.WatchURL(),
+		Extractor:    "youtube",
+		ExtractorKey: "Youtube",
+		Thumbnail:    v.PosterThumbnail.URL,
+		Thumbnails:   []infoThumbnail{},
+		Formats:      []infoFormat{},
+	}
+	if !v.PublishDate.IsZero() {
+		info.UploadDate = v.PublishDate.Format("20060102")
+	}
+
+	for i, thumbnail := range v.Thumbnails {
+		info.Thumbnails = append(info.Thumbnails, infoThumbnail{
+			ID:     strconv.Itoa(i),
+			URL:    thumbnail.URL,
+			Width:  thumbnail.Width,
+			Height: thumbnail.Height,
+		})
+	}
+
+	for i, chapter := range v.Chapters {
+		end := v.Duration
+		if i+1 < len(v.Chapters) {
+			end = v.Chapters[i+1].Start
+		}
+		info.Chapters = append(info.Chapters, infoChapter{
+			StartTime: chapter.Start.Seconds(),
+			EndTime:   end.Seconds(),
+			Title:     chapter.Title,
+		})
+	}
+
+	for i := range v.Formats {
+		format, err := c.infoFormat(&v.Formats[i])
+		if err != nil {
+			return nil, err
+		}
+		info.Formats = append(info.Formats, format)
+	}
+
+	switch len(selected) {
+	case 0:
+	case 1:
+		format, err := c.infoFormat(selected[0])
+		if err != nil {
+			return nil, err
+		}
+		info.infoFormat = &format
+	default:
+		var ids []string
+		combined := infoFormat{VCodec: "none", ACodec: "none"}
+		for _, f := range selected {
+			format, err := c.infoFormat(f)
+			if err != nil {
+				return nil, err
+			}
+			info.RequestedFormats = append(info.RequestedFormats, format)
+			ids = append(ids, format.FormatID)
+
+			if format.VCodec != "none" {
+				combined.VCodec, combined.Ext = format.VCodec, format.Ext
+				combined.Width, combined.Height, combined.FPS = format.Width, format.Height, format.FPS
+			}
+			if format.ACodec != "none" {
+				combined.ACodec, combined.ASR, combined.AudioChannels = format.ACodec, format.ASR, format.AudioChannels
+			}
+			combined.TBR += format.TBR
+		}
+		combined.FormatID = strings.Join(ids, "+")
+		info.infoFormat = &combined
+	}
+
+	return json.Marshal(info)
+}
+
+// infoFormat converts a format to the yt-dlp schema
+func (c *Client) infoFormat(format *Format) (infoFormat, error) {
+	container, codecs := parseMimeType(format.MimeType)
+
+	info := infoFormat{
+		FormatID:      strconv.Itoa(format.ItagNo),
+		FormatNote:    format.QualityLabel,
+		Ext:           container,
+		VCodec:        "none",
+		ACodec:        "none",
+		Width:         format.Width,
+		Height:        format.Height,
+		FPS:           format.FPS,
+		TBR:           float64(format.Bitrate) / 1000,
+		Filesize:      format.ContentLength,
+		AudioChannels: format.AudioChannels,
+	}
+	info.ASR, _ = strconv.Atoi(format.AudioSampleRate)
+	if format.URL != "" {
+		var err error
+		if info.URL, err = c.addPOToken(format.URL); err != nil {
+			return info, fmt.Errorf("itag %d: %w", format.ItagNo, err)
+		}
+	}
+
+	for _, codec := range codecs {
+		if hasCodecPrefix(audioCodecs, codec) {
+			info.ACodec = codec
+		} else {
+			info.VCodec = codec
+		}
+	}
+
+	// audio in mp4 containers is called m4a by yt-dlp
+	if container == "mp4" && info.VCodec == "none" {
+		info.Ext = "m4a"
+	}
+
+	return info, nil
+}
@@ -0,0 +1,84 @@
+package youtube
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_MarshalInfoJSON(t *testing.T) {
+	v := &Video{
+		ID:          "rFejpH_tAHM",
+		Title:       "dotGo 2015 - Rob Pike - Simplicity is Complicated",
+		Author:      "dotconferences",
+		Duration:    20 * time.Minute,
+		PublishDate: time.Date(2015, 12, 2, 0, 0, 0, 0, time.UTC),
+		Thumbnails:  Thumbnails{{URL: "https://i.ytimg.com/vi/rFejpH_tAHM/default.jpg", Width: 120, Height: 90}},
+		Chapters:    []Chapter{{Title: "Intro"}, {Title: "Talk", Start: time.Minute}},
+		Formats: FormatList{
+			{ItagNo: 137, URL: "https://example.com/137", MimeType: `video/mp4; codecs="avc1.640028"`, Width: 1920, Height: 1080, FPS: 30, Bitrate: 4000000, QualityLabel: "1080p"},
+			{ItagNo: 140, URL: "https://example.com/140", MimeType: `audio/mp4; codecs="mp4a.40.2"`, Bitrate: 128000, AudioSampleRate: "44100", AudioChannels: 2, ContentLength: 1234},
+			{ItagNo: 18, Cipher: "s=abc&sp=sig&url=https%3A%2F%2Fexample.com%2F18", MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Width: 640, Height: 360},
+		},
+	}
+
+	client := &Client{}
+	data, err := client.MarshalInfoJSON(v, &v.Formats[0], &v.Formats[1])
+	require.NoError(t, err)
+
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &info))
+
+	assert.Equal(t, "rFejpH_tAHM", info["id"])
+	assert.Equal(t, v.Title, info["title"])
+	assert.Equal(t, "dotconferences", info["uploader"])
+	assert.Equal(t, float64(1200), info["duration"])
+	assert.Equal(t, "20151202", info["upload_date"])
+	assert.Equal(t, "https://www.youtube.com/watch?v=rFejpH_tAHM", info["webpage_url"])
+	assert.Len(t, info["thumbnails"], 1)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"start_time": float64(0), "end_time": float64(60), "title": "Intro"},
+		map[string]interface{}{"start_time": float64(60), "end_time": float64(1200), "title": "Talk"},
+	}, info["chapters"])
+
+	// the combination of the selected formats
+	assert.Equal(t, "137+140", info["format_id"])
+	assert.Equal(t, "mp4", info["ext"])
+	assert.Equal(t, "avc1.640028", info["vcodec"])
+	assert.Equal(t, "mp4a.40.2", info["acodec"])
+	assert.Nil(t, info["url"])
+	assert.Len(t, info["requested_formats"], 2)
+
+	formats := info["formats"].([]interface{})
+	require.Len(t, formats, 3)
+	assert.Equal(t, map[string]interface{}{
+		"format_id":      "140",
+		"url":            "https://example.com/140",
+		"ext":            "m4a",
+		"vcodec":         "none",
+		"acodec":         "mp4a.40.2",
+		"tbr":            float64(128),
+		"filesize":       float64(1234),
+		"asr":            float64(44100),
+		"audio_channels": float64(2),
+	}, formats[1])
+	assert.Equal(t, "avc1.42001E", formats[2].(map[string]interface{})["vcodec"])
+	assert.Nil(t, formats[2].(map[string]interface{})["url"])
+
+	// a single selected format
+	data, err = client.MarshalInfoJSON(v, &v.Formats[0])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, "137", info["format_id"])
+	assert.Equal(t, "https://example.com/137", info["url"])
+
+	// the PO token is added to the URLs
+	client.POToken = "token"
+	data, err = client.MarshalInfoJSON(v, &v.Formats[1])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, "https://example.com/140?pot=token", info["url"])
+}
@@ -0,0 +1,120 @@
+package youtube
+
+// ItagInfo contains the typical properties of a format, independent of a specific video.
+// Codecs are empty if the format has no video or no audio.
+type ItagInfo struct {
+	Itag         int
+	Container    string
+	VideoCodec   string
+	AudioCodec   string
+	Height       int
+	FPS          int // zero for the default frame rate of the video
+	AudioBitrate int // kbit/s
+}
+
+// HasVideo reports whether the format contains video
+func (info ItagInfo) HasVideo() bool {
+	return info.VideoCodec != ""
+}
+
+// HasAudio reports whether the format contains audio
+func (info ItagInfo) HasAudio() bool {
+	return info.AudioCodec != ""
+}
+
+// KnownItags returns the well-known itags as reference data, e.g. to pre-populate quality menus.
+// Which of them are available depends on the video, see Video.Formats.
+func KnownItags() map[int]ItagInfo {
+	itags := make(map[int]ItagInfo, len(knownItags))
+	for itag, info := range knownItags {
+		info.Itag = itag
+		itags[itag] = info
+	}
+	return itags
+}
+
+// based on the itag table of youtube-dl
+var knownItags = map[int]ItagInfo{
+	// video with audio
+	5:  {Container: "flv", VideoCodec: "h263", AudioCodec: "mp3", Height: 240, AudioBitrate: 64},
+	6:  {Container: "flv", VideoCodec: "h263", AudioCodec: "mp3", Height: 270, AudioBitrate: 64},
+	13: {Container: "3gp", VideoCodec: "mp4v", AudioCodec: "aac"},
+	17: {Container: "3gp", VideoCodec: "mp4v", AudioCodec: "aac", Height: 144, AudioBitrate: 24},
+	18: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 360, AudioBitrate: 96},
+	22: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 720, AudioBitrate: 192},
+	34: {Container: "flv", VideoCodec: "avc1", AudioCodec: "aac", Height: 360, AudioBitrate: 128},
+	35: {Container: "flv", VideoCodec: "avc1", AudioCodec: "aac", Height: 480, AudioBitrate: 128},
+	36: {Container: "3gp", VideoCodec: "mp4v", AudioCodec: "aac", Height: 240},
+	37: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 1080, AudioBitrate: 192},
+	38: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 3072, AudioBitrate: 192},
+	43: {Container: "webm", VideoCodec: "vp8", AudioCodec: "vorbis", Height: 360, AudioBitrate: 128},
+	44: {Container: "webm", VideoCodec: "vp8", AudioCodec: "vorbis", Height: 480, AudioBitrate: 128},
+	45: {Container: "webm", VideoCodec: "vp8", AudioCodec: "vorbis", Height: 720, AudioBitrate: 192},
+	46: {Container: "webm", VideoCodec: "vp8", AudioCodec: "vorbis", Height: 1080, AudioBitrate: 192},
+	59: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 480, AudioBitrate: 128},
+	78: {Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 480, AudioBitrate: 128},
+
+	// DASH mp4 video
+	133: {Container: "mp4", VideoCodec: "avc1", Height: 240},
+	134: {Container: "mp4", VideoCodec: "avc1", Height: 360},
+	135: {Container: "mp4", VideoCodec: "avc1", Height: 480},
+	136: {Container: "mp4", VideoCodec: "avc1", Height: 720},
+	137: {Container: "mp4", VideoCodec: "avc1", Height: 1080},
+	138: {Container: "mp4", VideoCodec: "avc1"}, // height varies
+	160: {Container: "mp4", VideoCodec: "avc1", Height: 144},
+	212: {Container: "mp4", VideoCodec: "avc1", Height: 480},
+	264: {Container: "mp4", VideoCodec: "avc1", Height: 1440},
+	266: {Container: "mp4", VideoCodec: "avc1", Height: 2160},
+	298: {Container: "mp4", VideoCodec: "avc1", Height: 720, FPS: 60},
+	299: {Container: "mp4", VideoCodec: "avc1", Height: 1080, FPS: 60},
+
+	// DASH mp4 audio
+	139: {Container: "m4a", AudioCodec: "aac", AudioBitrate: 48},
+	140: {Container: "m4a", AudioCodec: "aac", AudioBitrate: 128},
+	141: {Container: "m4a", AudioCodec: "aac", AudioBitrate: 256},
+	256: {Container: "m4a", AudioCodec: "aac"},
+	258: {Container: "m4a", AudioCodec: "aac"},
+	325: {Container: "m4a", AudioCodec: "dtse"},
+	328: {Container: "m4a", AudioCodec: "ec-3"},
+
+	// DASH webm video
+	167: {Container: "webm", VideoCodec: "vp8", Height: 360},
+	168: {Container: "webm", VideoCodec: "vp8", Height: 480},
+	169: {Container: "webm", VideoCodec: "vp8", Height: 720},
+	170: {Container: "webm", VideoCodec: "vp8", Height: 1080},
+	218: {Container: "webm", VideoCodec: "vp8", Height: 480},
+	219: {Container: "webm", VideoCodec: "vp8", Height: 480},
+	242: {Container: "webm", VideoCodec: "vp9", Height: 240},
+	243: {Container: "webm", VideoCodec: "vp9", Height: 360},
+	244: {Container: "webm", VideoCodec: "vp9", Height: 480},
+	245: {Container: "webm", VideoCodec: "vp9", Height: 480},
+	246: {Container: "webm", VideoCodec: "vp9", Height: 480},
+	247: {Container: "webm", VideoCodec: "vp9", Height: 720},
+	248: {Container: "webm", VideoCodec: "vp9", Height: 1080},
+	271: {Container: "webm", VideoCodec: "vp9", Height: 1440},
+	272: {Container: "webm", VideoCodec: "vp9", Height: 2160},
+	278: {Container: "webm", VideoCodec: "vp9", Height: 144},
+	302: {Container: "webm", VideoCodec: "vp9", Height: 720, FPS: 60},
+	303: {Container: "webm", VideoCodec: "vp9", Height: 1080, FPS: 60},
+	308: {Container: "webm", VideoCodec: "vp9", Height: 1440, FPS: 60},
+	313: {Container: "webm", VideoCodec: "vp9", Height: 2160},
+	315: {Container: "webm", VideoCodec: "vp9", Height: 2160, FPS: 60},
+
+	// DASH webm audio
+	171: {Container: "webm", AudioCodec: "vorbis", AudioBitrate: 128},
+	172: {Container: "webm", AudioCodec: "vorbis", AudioBitrate: 256},
+	249: {Container: "webm", AudioCodec: "opus", AudioBitrate: 50},
+	250: {Container: "webm", AudioCodec: "opus", AudioBitrate: 70},
+	251: {Container: "webm", AudioCodec: "opus", AudioBitrate: 160},
+
+	// DASH mp4 AV1 video
+	394: {Container: "mp4", VideoCodec: "av01", Height: 144},
+	395: {Container: "mp4", VideoCodec: "av01", Height: 240},
+	396: {Container: "mp4", VideoCodec: "av01", Height: 360},
+	397: {Container: "mp4", VideoCodec: "av01", Height: 480},
+	398: {Container: "mp4", VideoCodec: "av01", Height: 720},
+	399: {Container: "mp4", VideoCodec: "av01", Height: 1080},
+	400: {Container: "mp4", VideoCodec: "av01", Height: 1440},
+	401: {Container: "mp4", VideoCodec: "av01", Height: 2160},
+	402: {Container: "mp4", VideoCodec: "av01", Height: 4320},
+}
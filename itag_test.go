@@ -16,3 +16,19 @@ func TestYoutube_GetItagInfo(t *testing.T) {
 	require.NoError(err)
 	require.Len(video.Formats, 24)
 }
+
+func TestKnownItags(t *testing.T) {
+	require := require.New(t)
+
+	itags := KnownItags()
+	require.Contains(itags, 18)
+	require.Equal(ItagInfo{Itag: 18, Container: "mp4", VideoCodec: "avc1", AudioCodec: "aac", Height: 360, AudioBitrate: 96}, itags[18])
+	require.True(itags[18].HasVideo())
+	require.True(itags[18].HasAudio())
+	require.False(itags[140].HasVideo())
+	require.True(itags[140].HasAudio())
+
+	// the reference data can't be modified
+	delete(itags, 18)
+	require.Contains(KnownItags(), 18)
+}
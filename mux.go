@@ -0,0 +1,35 @@
+package youtube
+
+// muxContainers lists the codec prefixes each container supports for a stream copy with ffmpeg
+var muxContainers = map[string]struct {
+	video []string
+	audio []string
+}{
+	"mp4":  {video: []string{"avc1", "av01", "vp09", "vp9", "hev1", "hvc1"}, audio: []string{"mp4a"}},
+	"webm": {video: []string{"vp8", "vp9", "vp09", "av01"}, audio: []string{"opus", "vorbis"}},
+}
+
+// CanMuxCopy reports whether a video-only and an audio-only format can be muxed into the container
+// of the video format without re-encoding, e.g. avc1 with mp4a or vp9 with opus.
+func CanMuxCopy(videoFormat, audioFormat *Format) bool {
+	container, _ := parseMimeType(videoFormat.MimeType)
+	return CanMuxCopyInto(container, videoFormat, audioFormat)
+}
+
+// CanMuxCopyInto reports whether a video-only and an audio-only format can be muxed into the given
+// container ("mp4" or "webm") without re-encoding.
+func CanMuxCopyInto(container string, videoFormat, audioFormat *Format) bool {
+	supported, ok := muxContainers[container]
+	if !ok {
+		return false
+	}
+
+	// both formats must contain a single stream
+	_, videoCodec := parseMimeType(videoFormat.MimeType)
+	_, audioCodec := parseMimeType(audioFormat.MimeType)
+	if len(videoCodec) != 1 || len(audioCodec) != 1 {
+		return false
+	}
+
+	return hasCodecPrefix(supported.video, videoCodec[0]) && hasCodecPrefix(supported.audio, audioCodec[0])
+}
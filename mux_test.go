@@ -0,0 +1,29 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanMuxCopy(t *testing.T) {
+	avc1 := &Format{MimeType: `video/mp4; codecs="avc1.640028"`}
+	av01 := &Format{MimeType: `video/mp4; codecs="av01.0.08M.08"`}
+	vp9 := &Format{MimeType: `video/webm; codecs="vp9"`}
+	muxed := &Format{MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`}
+	aac := &Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`}
+	opus := &Format{MimeType: `audio/webm; codecs="opus"`}
+
+	assert.True(t, CanMuxCopy(avc1, aac))
+	assert.True(t, CanMuxCopy(av01, aac))
+	assert.True(t, CanMuxCopy(vp9, opus))
+	assert.False(t, CanMuxCopy(avc1, opus))
+	assert.False(t, CanMuxCopy(vp9, aac))
+	assert.False(t, CanMuxCopy(muxed, aac))
+	assert.False(t, CanMuxCopy(aac, avc1))
+	assert.False(t, CanMuxCopy(&Format{MimeType: "invalid"}, aac))
+
+	assert.True(t, CanMuxCopyInto("mp4", vp9, aac))
+	assert.False(t, CanMuxCopyInto("mp4", vp9, opus))
+	assert.False(t, CanMuxCopyInto("mkv", vp9, opus))
+}
@@ -14,6 +14,8 @@ import (
 var (
 	playlistIDRegex    = regexp.MustCompile("^[A-Za-z0-9_-]{13,42}$")
 	playlistInURLRegex = regexp.MustCompile("[&?]list=([A-Za-z0-9_-]{13,42})(&.*)?$")
+	// podcasts on YouTube Music are browsed by their playlist ID prefixed with MPSP
+	podcastInURLRegex = regexp.MustCompile("/browse/MPSP([A-Za-z0-9_-]{13,42})([?&].*)?$")
 )
 
 type Playlist struct {
@@ -22,6 +24,10 @@ type Playlist struct {
 	Description string
 	Author      string
 	Videos      []*PlaylistEntry
+
+	// Continuations holds the continuation tokens in the order they were
+	// followed while crawling the playlist. It is meant for debugging only.
+	Continuations []string
 }
 
 type PlaylistEntry struct {
@@ -32,6 +38,28 @@ type PlaylistEntry struct {
 	Thumbnails Thumbnails
 }
 
+// TotalDuration returns the summed duration of all playlist entries
+func (p *Playlist) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, entry := range p.Videos {
+		total += entry.Duration
+	}
+	return total
+}
+
+// FormatSelector picks the formats to download for a video, either may be nil
+type FormatSelector func(v *Video) (videoFormat, audioFormat *Format)
+
+// TotalEstimatedDownloadSize returns the number of bytes to download all videos
+// with the formats chosen by the selector, see Video.EstimatedDownloadSize.
+func TotalEstimatedDownloadSize(videos []*Video, selector FormatSelector) int64 {
+	var total int64
+	for _, v := range videos {
+		total += v.EstimatedDownloadSize(selector(v))
+	}
+	return total
+}
+
 func extractPlaylistID(url string) (string, error) {
 	if playlistIDRegex.Match([]byte(url)) {
 		return url, nil
@@ -43,6 +71,10 @@ func extractPlaylistID(url string) (string, error) {
 		return matches[1], nil
 	}
 
+	if matches = podcastInURLRegex.FindStringSubmatch(url); matches != nil {
+		return matches[1], nil
+	}
+
 	return "", ErrInvalidPlaylist
 }
 
@@ -60,7 +92,8 @@ func extractPlaylistID(url string) (string, error) {
 // Thumbnails .thumbnails
 
 // TODO?: Author thumbnails: sidebar.playlistSidebarRenderer.items[0].playlistSidebarPrimaryInfoRenderer.thumbnailRenderer.playlistVideoThumbnailRenderer.thumbnail.thumbnails
-func (p *Playlist) parsePlaylistInfo(ctx context.Context, client *Client, body []byte) (err error) {
+// stop is optional and called for every entry in playlist order, paging ends as soon as it returns true.
+func (p *Playlist) parsePlaylistInfo(ctx context.Context, client *Client, body []byte, stop func(*PlaylistEntry) bool) (err error) {
 	var j *sjson.Json
 	j, err = sjson.NewJson(body)
 	if err != nil {
@@ -95,10 +128,15 @@ func (p *Playlist) parsePlaylistInfo(ctx context.Context, client *Client, body [
 		return err
 	}
 
-	p.Videos = entries
+	if p.appendEntries(entries, stop) {
+		return nil
+	}
 
 	for continuation != "" {
-		data := prepareInnertubePlaylistData(continuation, true, webClient)
+		p.Continuations = append(p.Continuations, continuation)
+		client.logf("playlist continuation: %s", continuation)
+
+		data := client.prepareInnertubePlaylistData(continuation, true, webClient)
 
 		body, err := client.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+webClient.key, data)
 		if err != nil {
@@ -122,12 +160,26 @@ func (p *Playlist) parsePlaylistInfo(ctx context.Context, client *Client, body [
 			return err
 		}
 
-		p.Videos, continuation = append(p.Videos, entries...), token
+		if p.appendEntries(entries, stop) {
+			return nil
+		}
+		continuation = token
 	}
 
 	return err
 }
 
+// appendEntries adds the entries to the playlist and reports whether stop requested to end the paging
+func (p *Playlist) appendEntries(entries []*PlaylistEntry, stop func(*PlaylistEntry) bool) bool {
+	for _, entry := range entries {
+		p.Videos = append(p.Videos, entry)
+		if stop != nil && stop(entry) {
+			return true
+		}
+	}
+	return false
+}
+
 func extractPlaylistEntries(data []byte) ([]*PlaylistEntry, string, error) {
 	var vids []*videosJSONExtractor
 
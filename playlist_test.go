@@ -1,9 +1,16 @@
 package youtube
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestYoutube_extractPlaylistID(t *testing.T) {
@@ -44,6 +51,18 @@ func TestYoutube_extractPlaylistID(t *testing.T) {
 			"RD-T4THwne8IE",
 			nil,
 		},
+		{
+			"pass-6 (music)",
+			"https://music.youtube.com/playlist?list=PLqAfPOrmacr963ATEroh67fbvjmTzTEx5",
+			"PLqAfPOrmacr963ATEroh67fbvjmTzTEx5",
+			nil,
+		},
+		{
+			"pass-7 (podcast)",
+			"https://music.youtube.com/browse/MPSPPLqAfPOrmacr963ATEroh67fbvjmTzTEx5",
+			"PLqAfPOrmacr963ATEroh67fbvjmTzTEx5",
+			nil,
+		},
 		{
 			"fail-1-playlist-id-44-char",
 			"https://www.youtube.com/watch?v=9UL390els7M&list=PLqAfPOrmacr963ATEroh67fbvjmTzTEx5X1212404244", "",
@@ -70,3 +89,62 @@ func TestYoutube_extractPlaylistID(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaylist_TotalDuration(t *testing.T) {
+	p := &Playlist{Videos: []*PlaylistEntry{
+		{Duration: time.Minute},
+		{Duration: 30 * time.Second},
+	}}
+
+	assert.Equal(t, 90*time.Second, p.TotalDuration())
+	assert.Zero(t, (&Playlist{}).TotalDuration())
+}
+
+func TestTotalEstimatedDownloadSize(t *testing.T) {
+	videos := []*Video{
+		{Formats: FormatList{{ContentLength: 100}, {ContentLength: 10}}},
+		{Formats: FormatList{{ContentLength: 200}, {ContentLength: 20}}},
+	}
+
+	selector := func(v *Video) (*Format, *Format) {
+		return &v.Formats[0], &v.Formats[1]
+	}
+	assert.EqualValues(t, 330, TotalEstimatedDownloadSize(videos, selector))
+}
+
+func TestPlaylist_parsePlaylistInfo_Continuations(t *testing.T) {
+	entry := func(id string) string {
+		return `{"playlistVideoRenderer": {"videoId": "` + id + `", "lengthSeconds": "60"}}`
+	}
+	continuation := func(token string) string {
+		return `{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "` + token + `"}}}}`
+	}
+	page := func(items ...string) string {
+		return `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [` + strings.Join(items, ",") + `]}}]}`
+	}
+
+	responses := map[string]string{
+		"page2": page(entry("bbbbbbbbbbb"), continuation("page3")),
+		"page3": page(entry("ccccccccccc")),
+	}
+	client := &Client{HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var request innertubeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(responses[request.Continuation])),
+		}
+	})}}
+
+	body := `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+		{"itemSectionRenderer": {"contents": [{"playlistVideoListRenderer": {"contents": [` + entry("aaaaaaaaaaa") + `,` + continuation("page2") + `]}}]}}
+	]}}}}]}}}`
+
+	p := &Playlist{ID: "PL59FEE129ADFF2B12"}
+	require.NoError(t, p.parsePlaylistInfo(context.Background(), client, []byte(body), nil))
+
+	assert.Len(t, p.Videos, 3)
+	assert.Equal(t, "ccccccccccc", p.Videos[2].ID)
+	assert.Equal(t, []string{"page2", "page3"}, p.Continuations)
+}
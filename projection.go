@@ -0,0 +1,30 @@
+package youtube
+
+// Projection is the mapping of the video frames for playback
+type Projection string
+
+const (
+	ProjectionRectangular Projection = "rectangular" // regular video
+	Projection360         Projection = "360"         // monoscopic 360° video
+	Projection360Stereo   Projection = "360s"        // stereoscopic 360° video, top/bottom
+	ProjectionMesh        Projection = "mesh"        // custom mesh, e.g. VR180
+)
+
+// IsSpherical reports whether the video has to be rendered on a sphere or mesh
+func (v *Video) IsSpherical() bool {
+	return v.Projection != "" && v.Projection != ProjectionRectangular
+}
+
+// parseProjection converts the projectionType of a format, defaulting to rectangular
+func parseProjection(projectionType string) Projection {
+	switch projectionType {
+	case "EQUIRECTANGULAR":
+		return Projection360
+	case "EQUIRECTANGULAR_THREED_TOP_BOTTOM":
+		return Projection360Stereo
+	case "MESH":
+		return ProjectionMesh
+	default:
+		return ProjectionRectangular
+	}
+}
@@ -0,0 +1,146 @@
+package youtube
+
+import (
+	"io"
+	"sync"
+)
+
+// RelayBuffer is a bounded ring buffer for relaying a stream to multiple readers with low latency.
+// A stream is copied into it with io.Copy, readers tail it independently. When the buffer is full
+// the oldest data is dropped and readers which fell behind continue with the oldest data available.
+type RelayBuffer struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	data    []byte
+	written int64 // total number of bytes ever written
+	closed  bool
+	err     error
+}
+
+// NewRelayBuffer creates a buffer holding at most size bytes, size must be positive
+func NewRelayBuffer(size int) *RelayBuffer {
+	b := &RelayBuffer{data: make([]byte, size)}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Write adds p to the buffer, dropping the oldest data if necessary. It never blocks on readers.
+func (b *RelayBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := len(p)
+	size := len(b.data)
+	if n > size {
+		// only the tail fits into the buffer
+		b.written += int64(n - size)
+		p = p[n-size:]
+	}
+
+	for len(p) > 0 {
+		copied := copy(b.data[b.written%int64(size):], p)
+		b.written += int64(copied)
+		p = p[copied:]
+	}
+
+	b.cond.Broadcast()
+	return n, nil
+}
+
+// Close marks the end of the stream, readers get io.EOF after reading the remaining data
+func (b *RelayBuffer) Close() error {
+	return b.CloseWithError(nil)
+}
+
+// CloseWithError marks the end of the stream, readers get err after reading the remaining data.
+// A nil err is returned as io.EOF.
+func (b *RelayBuffer) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		b.err = err
+		b.cond.Broadcast()
+	}
+	return nil
+}
+
+// NewReader returns a reader starting at the live edge, i.e. it receives the data written from now on
+func (b *RelayBuffer) NewReader() *RelayReader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &RelayReader{buffer: b, pos: b.written}
+}
+
+// RelayReader reads from a RelayBuffer at its own position
+type RelayReader struct {
+	buffer  *RelayBuffer
+	pos     int64
+	dropped int64
+	closed  bool
+}
+
+// Read blocks until data is available, the buffer or the reader is closed
+func (r *RelayReader) Read(p []byte) (int, error) {
+	b := r.buffer
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for r.pos == b.written && !b.closed && !r.closed {
+		b.cond.Wait()
+	}
+	if r.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if r.pos == b.written {
+		return 0, b.err
+	}
+
+	size := int64(len(b.data))
+	if oldest := b.written - size; r.pos < oldest {
+		// the reader fell behind, skip the overwritten data
+		r.dropped += oldest - r.pos
+		r.pos = oldest
+	}
+
+	available := b.written - r.pos
+	if int64(len(p)) > available {
+		p = p[:available]
+	}
+
+	n := 0
+	for n < len(p) {
+		copied := copy(p[n:], b.data[(r.pos+int64(n))%size:])
+		n += copied
+	}
+	r.pos += int64(n)
+	return n, nil
+}
+
+// Dropped returns the number of bytes this reader missed because it was too slow
+func (r *RelayReader) Dropped() int64 {
+	r.buffer.mu.Lock()
+	defer r.buffer.mu.Unlock()
+
+	return r.dropped
+}
+
+// Close stops the reader, a blocked Read returns io.ErrClosedPipe
+func (r *RelayReader) Close() error {
+	r.buffer.mu.Lock()
+	defer r.buffer.mu.Unlock()
+
+	r.closed = true
+	r.buffer.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,82 @@
+package youtube
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayBuffer_MultipleReaders(t *testing.T) {
+	require := require.New(t)
+
+	buffer := NewRelayBuffer(8)
+	first := buffer.NewReader()
+
+	_, err := buffer.Write([]byte("abc"))
+	require.NoError(err)
+
+	second := buffer.NewReader()
+	_, err = buffer.Write([]byte("def"))
+	require.NoError(err)
+	require.NoError(buffer.Close())
+
+	data, err := ioutil.ReadAll(first)
+	require.NoError(err)
+	assert.Equal(t, "abcdef", string(data))
+
+	data, err = ioutil.ReadAll(second)
+	require.NoError(err)
+	assert.Equal(t, "def", string(data))
+}
+
+func TestRelayBuffer_DropsOldestData(t *testing.T) {
+	require := require.New(t)
+
+	buffer := NewRelayBuffer(4)
+	reader := buffer.NewReader()
+
+	_, err := buffer.Write([]byte("abc"))
+	require.NoError(err)
+	_, err = buffer.Write([]byte("defghij"))
+	require.NoError(err)
+	require.NoError(buffer.Close())
+
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(err)
+	assert.Equal(t, "ghij", string(data))
+	assert.EqualValues(t, 6, reader.Dropped())
+}
+
+func TestRelayBuffer_BlockingRead(t *testing.T) {
+	buffer := NewRelayBuffer(16)
+	reader := buffer.NewReader()
+
+	go func() {
+		buffer.Write([]byte("live"))
+		buffer.CloseWithError(io.ErrUnexpectedEOF)
+	}()
+
+	p := make([]byte, 16)
+	n, err := io.ReadAtLeast(reader, p, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "live", string(p[:n]))
+
+	_, err = reader.Read(p)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestRelayReader_Close(t *testing.T) {
+	reader := NewRelayBuffer(16).NewReader()
+
+	done := make(chan error)
+	go func() {
+		_, err := reader.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	require.NoError(t, reader.Close())
+	assert.Equal(t, io.ErrClosedPipe, <-done)
+}
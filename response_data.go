@@ -10,7 +10,12 @@ type playerResponseData struct {
 				PlaybackMode string `json:"playbackMode"`
 			} `json:"miniplayerRenderer"`
 		} `json:"miniplayer"`
-		ContextParams string `json:"contextParams"`
+		ContextParams  string `json:"contextParams"`
+		Offlineability struct {
+			OfflineabilityRenderer struct {
+				Offlineable bool `json:"offlineable"`
+			} `json:"offlineabilityRenderer"`
+		} `json:"offlineability"`
 	} `json:"playabilityStatus"`
 	StreamingData struct {
 		ExpiresInSeconds string   `json:"expiresInSeconds"`
@@ -19,6 +24,27 @@ type playerResponseData struct {
 		DashManifestURL  string   `json:"dashManifestUrl"`
 		HlsManifestURL   string   `json:"hlsManifestUrl"`
 	} `json:"streamingData"`
+	PlayerConfig struct {
+		AudioConfig struct {
+			LoudnessDb           float64 `json:"loudnessDb"`
+			PerceptualLoudnessDb float64 `json:"perceptualLoudnessDb"`
+		} `json:"audioConfig"`
+	} `json:"playerConfig"`
+	Captions struct {
+		PlayerCaptionsTracklistRenderer struct {
+			CaptionTracks []captionTrackData `json:"captionTracks"`
+			AudioTracks   []struct {
+				DefaultCaptionTrackIndex *int   `json:"defaultCaptionTrackIndex"`
+				CaptionsInitialState     string `json:"captionsInitialState"`
+			} `json:"audioTracks"`
+			DefaultAudioTrackIndex int `json:"defaultAudioTrackIndex"`
+		} `json:"playerCaptionsTracklistRenderer"`
+	} `json:"captions"`
+	Storyboards struct {
+		PlayerStoryboardSpecRenderer struct {
+			Spec string `json:"spec"`
+		} `json:"playerStoryboardSpecRenderer"`
+	} `json:"storyboards"`
 	VideoDetails struct {
 		VideoID          string   `json:"videoId"`
 		Title            string   `json:"title"`
@@ -42,11 +68,7 @@ type playerResponseData struct {
 	Microformat struct {
 		PlayerMicroformatRenderer struct {
 			Thumbnail struct {
-				Thumbnails []struct {
-					URL    string `json:"url"`
-					Width  int    `json:"width"`
-					Height int    `json:"height"`
-				} `json:"thumbnails"`
+				Thumbnails Thumbnails `json:"thumbnails"`
 			} `json:"thumbnail"`
 			Title struct {
 				SimpleText string `json:"simpleText"`
@@ -101,12 +123,37 @@ type Format struct {
 		Start string `json:"start"`
 		End   string `json:"end"`
 	} `json:"indexRange"`
+
+	// QualityRank is the quality of the format relative to the other formats of the video, from
+	// 0 for the lowest to 1 for the best. Formats with video are ranked by resolution and bitrate,
+	// audio-only formats among themselves by bitrate.
+	QualityRank float64 `json:"-"`
+}
+
+type captionTrackData struct {
+	BaseURL        string     `json:"baseUrl"`
+	Name           simpleText `json:"name"`
+	VssID          string     `json:"vssId"`
+	LanguageCode   string     `json:"languageCode"`
+	Kind           string     `json:"kind"`
+	IsTranslatable bool       `json:"isTranslatable"`
+}
+
+type simpleText struct {
+	SimpleText string `json:"simpleText"`
+	Runs       []struct {
+		Text string `json:"text"`
+	} `json:"runs"`
 }
 
-type Thumbnails []Thumbnail
+func (st simpleText) String() string {
+	if st.SimpleText != "" {
+		return st.SimpleText
+	}
 
-type Thumbnail struct {
-	URL    string
-	Width  uint
-	Height uint
+	var text string
+	for _, run := range st.Runs {
+		text += run.Text
+	}
+	return text
 }
@@ -0,0 +1,43 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type retryBudgetKey struct{}
+
+// retryBudget is the number of retries left for all requests of an operation
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// WithRetryBudget returns a context limiting the retries of all requests made with it, e.g. to
+// share one budget across several downloads. The budget isn't replaced if ctx already has one.
+// A budget of zero or less doesn't limit the retries.
+func WithRetryBudget(ctx context.Context, retries int) context.Context {
+	if retries <= 0 || ctx.Value(retryBudgetKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: retries})
+}
+
+// spendRetry takes one retry from the budget of ctx, if there is one.
+// The error which would have been retried is returned wrapped in ErrRetryBudgetExhausted if there is none left.
+func spendRetry(ctx context.Context, err error) error {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return nil
+	}
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	if budget.remaining <= 0 {
+		return fmt.Errorf("%w, last error: %v", ErrRetryBudgetExhausted, err)
+	}
+	budget.remaining--
+	return nil
+}
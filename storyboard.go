@@ -0,0 +1,191 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // storyboard sheets are JPEG images
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Storyboard is a level of preview frames, which are delivered as grids on sheets
+type Storyboard struct {
+	Level    int
+	Width    int // width of a frame
+	Height   int // height of a frame
+	Count    int // total number of frames
+	Columns  int // frames per row on a sheet
+	Rows     int // rows on a sheet
+	Interval time.Duration
+
+	url  string
+	name string
+	sigh string
+}
+
+// parseStoryboardSpec parses a spec like
+// https://i.ytimg.com/sb/ID/storyboard3_L$L/$N.jpg?sqp=...|48#27#100#10#10#0#default#rs$...|80#45#140#10#10#10000#M$M#rs$...
+// Levels with an invalid spec are skipped.
+func parseStoryboardSpec(spec string) []Storyboard {
+	parts := strings.Split(spec, "|")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	var storyboards []Storyboard
+	for level, part := range parts[1:] {
+		fields := strings.Split(part, "#")
+		if len(fields) < 8 {
+			continue
+		}
+
+		var numbers [6]int
+		valid := true
+		for i := range numbers {
+			n, err := strconv.Atoi(fields[i])
+			if err != nil || n < 0 {
+				valid = false
+				break
+			}
+			numbers[i] = n
+		}
+		if !valid || numbers[0] == 0 || numbers[1] == 0 || numbers[3] == 0 || numbers[4] == 0 {
+			continue
+		}
+
+		storyboards = append(storyboards, Storyboard{
+			Level:    level,
+			Width:    numbers[0],
+			Height:   numbers[1],
+			Count:    numbers[2],
+			Columns:  numbers[3],
+			Rows:     numbers[4],
+			Interval: time.Duration(numbers[5]) * time.Millisecond,
+			url:      parts[0],
+			name:     fields[6],
+			sigh:     fields[7],
+		})
+	}
+
+	return storyboards
+}
+
+// SheetURLs returns the URLs of all sheets of the storyboard
+func (sb *Storyboard) SheetURLs() []string {
+	perSheet := sb.Columns * sb.Rows
+	sheets := (sb.Count + perSheet - 1) / perSheet
+
+	urls := make([]string, 0, sheets)
+	for i := 0; i < sheets; i++ {
+		name := strings.ReplaceAll(sb.name, "$M", strconv.Itoa(i))
+		sheetURL := strings.ReplaceAll(sb.url, "$L", strconv.Itoa(sb.Level))
+		sheetURL = strings.ReplaceAll(sheetURL, "$N", name)
+		urls = append(urls, addSigh(sheetURL, sb.sigh))
+	}
+	return urls
+}
+
+// addSigh adds the signature of a storyboard level to the query of a sheet URL
+func addSigh(sheetURL, sigh string) string {
+	if sigh == "" {
+		return sheetURL
+	}
+
+	uri, err := url.Parse(sheetURL)
+	if err != nil {
+		return sheetURL
+	}
+
+	query := uri.Query()
+	query.Set("sigh", sigh)
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
+
+// GetStoryboardImages returns the frames of a storyboard level
+func (c *Client) GetStoryboardImages(video *Video, level int) ([]image.Image, error) {
+	return c.GetStoryboardImagesContext(context.Background(), video, level)
+}
+
+// GetStoryboardImagesContext downloads the sheets of a storyboard level with a context
+// and returns the frames cut out of the sheets' grid.
+func (c *Client) GetStoryboardImagesContext(ctx context.Context, video *Video, level int) ([]image.Image, error) {
+	var sb *Storyboard
+	for i := range video.Storyboards {
+		if video.Storyboards[i].Level == level {
+			sb = &video.Storyboards[i]
+			break
+		}
+	}
+	if sb == nil {
+		return nil, fmt.Errorf("storyboard level %d not found", level)
+	}
+
+	frames := make([]image.Image, 0, sb.Count)
+	for _, url := range sb.SheetURLs() {
+		resp, err := c.httpGet(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		sheet, _, err := image.Decode(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode storyboard sheet: %w", err)
+		}
+
+		frames = append(frames, sb.cutFrames(sheet, sb.Count-len(frames))...)
+	}
+
+	return frames, nil
+}
+
+// cutFrames returns up to limit frames of a sheet, row by row
+func (sb *Storyboard) cutFrames(sheet image.Image, limit int) []image.Image {
+	sub, ok := sheet.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil
+	}
+
+	bounds := sheet.Bounds()
+	var frames []image.Image
+	for row := 0; row < sb.Rows; row++ {
+		for col := 0; col < sb.Columns && len(frames) < limit; col++ {
+			origin := bounds.Min.Add(image.Pt(col*sb.Width, row*sb.Height))
+			frame := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(sb.Width, sb.Height))}.Intersect(bounds)
+			if frame.Empty() {
+				// the last sheet may contain less frames
+				return frames
+			}
+			frames = append(frames, sub.SubImage(frame))
+		}
+	}
+	return frames
+}
+
+// ContactSheet stitches the frames into a single image with the given number of columns.
+// All frames are expected to have the size of the first one.
+func ContactSheet(frames []image.Image, columns int) image.Image {
+	if len(frames) == 0 || columns < 1 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+
+	size := frames[0].Bounds().Size()
+	rows := (len(frames) + columns - 1) / columns
+	if len(frames) < columns {
+		columns = len(frames)
+	}
+
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*size.X, rows*size.Y))
+	for i, frame := range frames {
+		origin := image.Pt(i%columns*size.X, i/columns*size.Y)
+		draw.Draw(sheet, image.Rectangle{Min: origin, Max: origin.Add(size)}, frame, frame.Bounds().Min, draw.Src)
+	}
+	return sheet
+}
@@ -0,0 +1,76 @@
+package youtube
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStoryboardSpec = "https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L$L/$N.jpg?sqp=abc|48#27#100#10#10#0#default#rs$AAA|80#45#140#10#10#10000#M$M#rs$BBB"
+
+func TestParseStoryboardSpec(t *testing.T) {
+	storyboards := parseStoryboardSpec(testStoryboardSpec)
+	require.Len(t, storyboards, 2)
+
+	sb := storyboards[1]
+	assert.Equal(t, 1, sb.Level)
+	assert.Equal(t, 80, sb.Width)
+	assert.Equal(t, 45, sb.Height)
+	assert.Equal(t, 140, sb.Count)
+	assert.Equal(t, 10, sb.Columns)
+	assert.Equal(t, 10, sb.Rows)
+	assert.Equal(t, 10*time.Second, sb.Interval)
+	assert.Equal(t, []string{
+		"https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L1/M0.jpg?sigh=rs%24BBB&sqp=abc",
+		"https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L1/M1.jpg?sigh=rs%24BBB&sqp=abc",
+	}, sb.SheetURLs())
+
+	assert.Equal(t, []string{"https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L0/default.jpg?sigh=rs%24AAA&sqp=abc"}, storyboards[0].SheetURLs())
+
+	// templates without a query get one
+	sb = parseStoryboardSpec("https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L$L/$N.jpg|48#27#1#1#1#0#default#rs$AAA")[0]
+	assert.Equal(t, []string{"https://i.ytimg.com/sb/rFejpH_tAHM/storyboard3_L0/default.jpg?sigh=rs%24AAA"}, sb.SheetURLs())
+
+	assert.Empty(t, parseStoryboardSpec(""))
+	assert.Empty(t, parseStoryboardSpec("https://i.ytimg.com/sb/x|invalid"))
+}
+
+func TestGetStoryboardImages(t *testing.T) {
+	// a sheet of 2x2 frames, each 10x5 pixels and of a different gray
+	sheet := image.NewGray(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			sheet.SetGray(x, y, color.Gray{Y: uint8(x/10*50 + y/5*100)})
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, jpeg.Encode(w, sheet, &jpeg.Options{Quality: 100}))
+	}))
+	defer server.Close()
+
+	video := &Video{Storyboards: parseStoryboardSpec(server.URL + "/$L/$N.jpg?a=b|10#5#3#2#2#1000#M$M#sig")}
+	frames, err := testClient.GetStoryboardImagesContext(context.Background(), video, 0)
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+
+	for i, want := range []uint8{0, 50, 100} {
+		assert.Equal(t, image.Pt(10, 5), frames[i].Bounds().Size())
+		gray := color.GrayModel.Convert(frames[i].At(frames[i].Bounds().Min.X+5, frames[i].Bounds().Min.Y+2)).(color.Gray)
+		assert.InDelta(t, want, gray.Y, 3)
+	}
+
+	stitched := ContactSheet(frames, 2)
+	assert.Equal(t, image.Rect(0, 0, 20, 10), stitched.Bounds())
+
+	_, err = testClient.GetStoryboardImagesContext(context.Background(), video, 5)
+	assert.EqualError(t, err, "storyboard level 5 not found")
+}
@@ -0,0 +1,73 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// resilientStream requests the stream again from the current offset if the connection drops
+type resilientStream struct {
+	ctx     context.Context
+	client  *Client
+	video   *Video
+	format  *Format
+	stream  io.ReadCloser
+	pos     int64
+	retries int
+}
+
+func (s *resilientStream) Read(p []byte) (int, error) {
+	for {
+		n, err := s.stream.Read(p)
+		s.pos += int64(n)
+
+		if err == nil || (err == io.EOF && s.pos >= s.format.ContentLength) {
+			return n, err
+		}
+		if n > 0 {
+			// return the data first, the error is returned again by the next read
+			return n, nil
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if !s.retryable(err) || s.retries >= s.client.StreamRetries {
+			return 0, err
+		}
+		if err := spendRetry(s.ctx, err); err != nil {
+			return 0, err
+		}
+
+		s.retries++
+		s.client.logf("stream of video %s dropped at byte %d, retry %d: %v", s.video.ID, s.pos, s.retries, err)
+
+		// back off a little, the network might need some time to recover
+		select {
+		case <-s.ctx.Done():
+			return 0, s.ctx.Err()
+		case <-time.After(time.Duration(s.retries) * 500 * time.Millisecond):
+		}
+
+		s.stream.Close()
+		s.stream, err = s.client.getStreamFrom(s.ctx, s.video, s.format, s.pos)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// retryable reports whether requesting the stream again could help
+func (s *resilientStream) retryable(err error) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr ErrUnexpectedStatusCode
+	return !errors.As(err, &statusErr)
+}
+
+func (s *resilientStream) Close() error {
+	return s.stream.Close()
+}
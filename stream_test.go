@@ -0,0 +1,244 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStream_Retries(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var start, end int
+		_, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		require.NoError(t, err)
+		if end >= len(data) {
+			end = len(data) - 1
+		}
+
+		w.Header().Set("Content-Length", fmt.Sprint(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+
+		// the first connection drops in the middle of the body
+		if requests == 1 {
+			_, _ = w.Write(data[start : start+400])
+			return
+		}
+		_, _ = w.Write(data[start : end+1])
+	}))
+	defer server.Close()
+
+	video := &Video{ID: "test"}
+	format := &Format{URL: server.URL, ContentLength: int64(len(data))}
+
+	t.Run("without retries", func(t *testing.T) {
+		requests = 0
+		client := Client{}
+		stream, _, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		_, err = io.ReadAll(stream)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("with retries", func(t *testing.T) {
+		requests = 0
+		client := Client{StreamRetries: 1}
+		stream, size, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		got, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(data), size)
+		assert.Equal(t, data, got)
+		assert.Equal(t, 2, requests)
+	})
+}
+
+func TestGetStream_ChunkRetries(t *testing.T) {
+	data := []byte("chunked data")
+
+	failures := 0
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	video := &Video{ID: "test"}
+	format := &Format{URL: server.URL, ContentLength: int64(len(data))}
+
+	t.Run("recovers", func(t *testing.T) {
+		failures, requests = 1, 0
+		client := Client{ChunkRetries: 1}
+		stream, _, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		got, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		failures, requests = 3, 0
+		client := Client{ChunkRetries: 1}
+		stream, _, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		_, err = io.ReadAll(stream)
+		var chunkErr *ErrChunkFailed
+		require.ErrorAs(t, err, &chunkErr)
+		assert.EqualValues(t, 0, chunkErr.Start)
+		assert.EqualValues(t, len(data)-1, chunkErr.End)
+		assert.Equal(t, 2, chunkErr.Attempts)
+		assert.ErrorIs(t, err, ErrUnexpectedStatusCode(http.StatusServiceUnavailable))
+	})
+}
+
+func TestGetStream_TotalRetryBudget(t *testing.T) {
+	data := []byte("chunked data")
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	video := &Video{ID: "test"}
+	format := &Format{URL: server.URL, ContentLength: int64(len(data))}
+
+	client := Client{ChunkRetries: 5, TotalRetryBudget: 1}
+	stream, _, err := client.GetStreamContext(context.Background(), video, format)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = io.ReadAll(stream)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, 2, requests)
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	ctx := WithRetryBudget(context.Background(), 2)
+	assert.Same(t, ctx, WithRetryBudget(ctx, 5), "an existing budget is kept")
+
+	assert.NoError(t, spendRetry(ctx, io.EOF))
+	assert.NoError(t, spendRetry(ctx, io.EOF))
+	err := spendRetry(ctx, io.EOF)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Contains(t, err.Error(), "EOF")
+
+	// no budget, no limit
+	assert.NoError(t, spendRetry(context.Background(), io.EOF))
+}
+
+func TestGetStream_AutoRefreshOnStreamError(t *testing.T) {
+	// the second chunk is forbidden for the stale URL
+	const size = 10_000_100
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		_, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		require.NoError(t, err)
+		if end >= size {
+			end = size - 1
+		}
+
+		mu.Lock()
+		ranges = append(ranges, r.URL.Path+" "+r.Header.Get("Range"))
+		mu.Unlock()
+
+		if (start > 0 && r.URL.Path == "/stale") || r.URL.Path == "/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Length", fmt.Sprint(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(bytes.Repeat([]byte{'x'}, end-start+1))
+	}))
+	defer server.Close()
+
+	// the fresh player response of the refresh, answered without network
+	refreshes := 0
+	freshURL := server.URL + "/fresh"
+	transport := roundTripFunc(func(req *http.Request) *http.Response {
+		var body string
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/embed/"):
+			body = `"jsUrl":"/s/player/abcdef/player_ias.vflset/en_US/base.js"`
+		case strings.HasPrefix(req.URL.Path, "/s/player/"):
+			body = `var a={b:1,signatureTimestamp:19000}`
+		case req.URL.Path == "/youtubei/v1/player":
+			refreshes++
+			body = fmt.Sprintf(`{"playabilityStatus": {"status": "OK"}, "streamingData": {"adaptiveFormats": [{"itag": 18, "url": %q, "contentLength": "%d"}]}}`, freshURL, size)
+		default:
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			require.NoError(t, err)
+			return resp
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	})
+
+	video := &Video{ID: "test"}
+	format := &Format{ItagNo: 18, URL: server.URL + "/stale", ContentLength: size}
+
+	t.Run("refreshed", func(t *testing.T) {
+		refreshes, ranges = 0, nil
+		client := Client{AutoRefreshOnStreamError: true, HTTPClient: &http.Client{Transport: transport}}
+		stream, _, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		n, err := io.Copy(io.Discard, stream)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, n)
+		assert.Equal(t, 1, refreshes)
+		assert.Equal(t, []string{
+			"/stale bytes=0-9999999",
+			"/stale bytes=10000000-19999999",
+			"/fresh bytes=10000000-19999999",
+		}, ranges)
+	})
+
+	t.Run("forbidden after refresh", func(t *testing.T) {
+		refreshes, ranges = 0, nil
+		freshURL = server.URL + "/forbidden"
+		client := Client{AutoRefreshOnStreamError: true, HTTPClient: &http.Client{Transport: transport}}
+		stream, _, err := client.GetStreamContext(context.Background(), video, format)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		_, err = io.Copy(io.Discard, stream)
+		assert.Equal(t, ErrUnexpectedStatusCode(http.StatusForbidden), err)
+		assert.Equal(t, 1, refreshes)
+	})
+}
@@ -0,0 +1,66 @@
+package youtube
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+)
+
+// thumbnailSizeRegex matches the size suffix of resized thumbnails, e.g. "=w120-h90-..."
+var thumbnailSizeRegex = regexp.MustCompile(`=w(\d+)-h(\d+)`)
+
+type Thumbnails []Thumbnail
+
+type Thumbnail struct {
+	URL    string
+	Width  uint
+	Height uint
+}
+
+// UnmarshalJSON infers missing dimensions from the size suffix of the URL
+func (t *Thumbnail) UnmarshalJSON(b []byte) error {
+	type thumbnail Thumbnail // avoids recursion
+	if err := json.Unmarshal(b, (*thumbnail)(t)); err != nil {
+		return err
+	}
+
+	if t.Width == 0 || t.Height == 0 {
+		if matches := thumbnailSizeRegex.FindStringSubmatch(t.URL); matches != nil {
+			width, _ := strconv.ParseUint(matches[1], 10, 32)
+			height, _ := strconv.ParseUint(matches[2], 10, 32)
+			t.Width, t.Height = uint(width), uint(height)
+		}
+	}
+
+	return nil
+}
+
+// Nearest returns the thumbnail with the width closest to the given one or nil if the list is empty.
+// Thumbnails without known dimensions are only returned if there is no other one.
+func (list Thumbnails) Nearest(width uint) *Thumbnail {
+	var nearest *Thumbnail
+	var nearestDiff uint
+
+	for i := range list {
+		thumbnail := &list[i]
+
+		var diff uint
+		if thumbnail.Width > width {
+			diff = thumbnail.Width - width
+		} else {
+			diff = width - thumbnail.Width
+		}
+
+		switch {
+		case nearest == nil:
+		case thumbnail.Width == 0:
+			continue
+		case nearest.Width != 0 && diff >= nearestDiff:
+			continue
+		}
+
+		nearest, nearestDiff = thumbnail, diff
+	}
+
+	return nearest
+}
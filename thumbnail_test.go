@@ -0,0 +1,39 @@
+package youtube
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnail_UnmarshalJSON(t *testing.T) {
+	var thumbnails Thumbnails
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"url": "https://i.ytimg.com/vi/BaW_jenozKc/default.jpg", "width": 120, "height": 90},
+		{"url": "https://yt3.ggpht.com/abc=w176-h176-c-k-c0x00ffffff-no-rj"},
+		{"url": "https://i.ytimg.com/vi/BaW_jenozKc/maxresdefault.jpg"}
+	]`), &thumbnails))
+
+	assert.Equal(t, Thumbnail{URL: "https://i.ytimg.com/vi/BaW_jenozKc/default.jpg", Width: 120, Height: 90}, thumbnails[0])
+	assert.Equal(t, uint(176), thumbnails[1].Width)
+	assert.Equal(t, uint(176), thumbnails[1].Height)
+	assert.Zero(t, thumbnails[2].Width)
+}
+
+func TestThumbnails_Nearest(t *testing.T) {
+	thumbnails := Thumbnails{
+		{URL: "unknown"},
+		{URL: "small", Width: 120, Height: 90},
+		{URL: "medium", Width: 320, Height: 180},
+		{URL: "large", Width: 1280, Height: 720},
+	}
+
+	assert.Equal(t, "small", thumbnails.Nearest(0).URL)
+	assert.Equal(t, "medium", thumbnails.Nearest(300).URL)
+	assert.Equal(t, "medium", thumbnails.Nearest(700).URL)
+	assert.Equal(t, "large", thumbnails.Nearest(4000).URL)
+	assert.Equal(t, "unknown", Thumbnails{{URL: "unknown"}}.Nearest(100).URL)
+	assert.Nil(t, Thumbnails{}.Nearest(100))
+}
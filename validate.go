@@ -0,0 +1,75 @@
+package youtube
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxConcurrentProbes limits the parallel probes of validateStreamURLs, to not trigger rate limiting
+const maxConcurrentProbes = 4
+
+// validateStreamURLs removes the formats whose stream URL can't be loaded
+func (c *Client) validateStreamURLs(ctx context.Context, v *Video) error {
+	// the URLs are resolved one after another, deciphering uses the player cache
+	urls := make([]string, len(v.Formats))
+	for i := range v.Formats {
+		urls[i], _ = c.GetStreamURLContext(ctx, v, &v.Formats[i])
+	}
+
+	alive := make([]bool, len(v.Formats))
+	probes := make(chan struct{}, maxConcurrentProbes)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		if url == "" {
+			continue
+		}
+
+		wg.Add(1)
+		probes <- struct{}{}
+		go func(i int, url string) {
+			defer wg.Done()
+			alive[i] = c.probeStreamURL(ctx, url)
+			<-probes
+		}(i, url)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	formats := v.Formats[:0]
+	for i, format := range v.Formats {
+		if alive[i] {
+			formats = append(formats, format)
+		} else {
+			c.logf("dropping format with itag %d of video %s, its stream URL isn't reachable", format.ItagNo, v.ID)
+		}
+	}
+	v.Formats = formats
+
+	if len(v.Formats) == 0 {
+		return ErrNoFormats
+	}
+	return nil
+}
+
+// probeStreamURL reports whether the first byte of the stream can be loaded
+func (c *Client) probeStreamURL(ctx context.Context, url string) bool {
+	req, err := newStreamRequest(ctx, url)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := c.httpDo(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode == http.StatusPartialContent || resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,72 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_validateStreamURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+		if r.URL.Query().Get("itag") == "22" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte{0})
+	}))
+	defer server.Close()
+
+	v := &Video{ID: "rFejpH_tAHM", Formats: FormatList{
+		{ItagNo: 18, URL: server.URL + "/videoplayback?itag=18"},
+		{ItagNo: 22, URL: server.URL + "/videoplayback?itag=22"},
+		{ItagNo: 140, URL: server.URL + "/videoplayback?itag=140"},
+		{ItagNo: 251}, // neither URL nor cipher
+	}}
+
+	require.NoError(t, (&Client{}).validateStreamURLs(context.Background(), v))
+
+	var itags []int
+	for _, format := range v.Formats {
+		itags = append(itags, format.ItagNo)
+	}
+	assert.Equal(t, []int{18, 140}, itags)
+
+	v = &Video{ID: "rFejpH_tAHM", Formats: FormatList{{ItagNo: 22, URL: server.URL + "/videoplayback?itag=22"}}}
+	assert.ErrorIs(t, (&Client{}).validateStreamURLs(context.Background(), v), ErrNoFormats)
+}
+
+func TestClient_validateStreamURLs_Concurrency(t *testing.T) {
+	var running, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer server.Close()
+
+	v := &Video{ID: "rFejpH_tAHM"}
+	for i := 0; i < 30; i++ {
+		v.Formats = append(v.Formats, Format{ItagNo: i, URL: fmt.Sprintf("%s/videoplayback?itag=%d", server.URL, i)})
+	}
+
+	require.NoError(t, (&Client{}).validateStreamURLs(context.Background(), v))
+	assert.Len(t, v.Formats, 30)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(maxConcurrentProbes))
+}
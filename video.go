@@ -20,8 +20,36 @@ type Video struct {
 	PublishDate     time.Time
 	Formats         FormatList
 	Thumbnails      Thumbnails
-	DASHManifestURL string // URI of the DASH manifest file
-	HLSManifestURL  string // URI of the HLS manifest file
+	CaptionTracks   CaptionTracks
+	Storyboards     []Storyboard
+	DASHManifestURL string        // URI of the DASH manifest file
+	HLSManifestURL  string        // URI of the HLS manifest file
+	Offlineable     bool          // whether YouTube Premium allows saving the video offline
+	RequestedStart  time.Duration // start time given by the t or start parameter of the requested URL
+	ExpiresAt       time.Time     // time after which the stream URLs are no longer valid, zero if unknown
+	Projection      Projection    // how the frames are mapped for playback, e.g. for 360° videos
+	Dislikes        int64         // number of dislikes from the Client.DislikeProvider, zero if unknown
+	PosterThumbnail Thumbnail     // thumbnail shown before playback, zero if unknown
+
+	// DefaultCaptionLanguage is the language code of the caption track the creator turned on by default.
+	// It's empty if captions are off by default.
+	DefaultCaptionLanguage string
+
+	// Loudness of the audio, e.g. to normalize the volume across downloads. Zero if unknown.
+	LoudnessDb           float64
+	PerceptualLoudnessDb float64
+
+	// The following metadata is read from the watch next endpoint, it's only set by Client.GetVideoDetails
+
+	DescriptionLinks []Link        // links and timestamps in the description
+	KeyMoments       []KeyMoment   // moments highlighted by YouTube, distinct from chapters
+	ProductTags      []ProductTag  // products tagged for shopping
+	DerivedClips     []DerivedClip // shorts derived from or remixing the video
+	Chapters         []Chapter     // chapters of the description, or the automatically generated ones
+	CommentsDisabled bool          // whether the comment section is turned off
+
+	// continuation of the chapters, long streams have more chapters than fit into one response
+	chaptersContinuation string
 }
 
 const dateFormat = "2006-01-02"
@@ -73,6 +101,10 @@ func (v *Video) isVideoDownloadable(prData playerResponseData, isVideoPage bool)
 	case "OK":
 		return nil
 	case "LOGIN_REQUIRED":
+		if strings.Contains(prData.PlayabilityStatus.Reason, "not a bot") {
+			return &ErrBotCheck{Reason: prData.PlayabilityStatus.Reason}
+		}
+
 		// for some reason they use same status message for age-restricted and private videos
 		if strings.HasPrefix(prData.PlayabilityStatus.Reason, "This video is private") {
 			return ErrVideoPrivate
@@ -95,6 +127,16 @@ func (v *Video) extractDataFromPlayerResponse(prData playerResponseData) error {
 	v.Description = prData.VideoDetails.ShortDescription
 	v.Author = prData.VideoDetails.Author
 	v.Thumbnails = prData.VideoDetails.Thumbnail.Thumbnails
+	v.PosterThumbnail = Thumbnail{}
+	if poster := prData.Microformat.PlayerMicroformatRenderer.Thumbnail.Thumbnails; len(poster) > 0 {
+		v.PosterThumbnail = poster[0]
+	} else if len(v.Thumbnails) > 0 {
+		// the thumbnails are ordered by size, the largest one is the poster
+		v.PosterThumbnail = v.Thumbnails[len(v.Thumbnails)-1]
+	}
+	v.Offlineable = prData.PlayabilityStatus.Offlineability.OfflineabilityRenderer.Offlineable
+	v.LoudnessDb = prData.PlayerConfig.AudioConfig.LoudnessDb
+	v.PerceptualLoudnessDb = prData.PlayerConfig.AudioConfig.PerceptualLoudnessDb
 
 	if seconds, _ := strconv.Atoi(prData.Microformat.PlayerMicroformatRenderer.LengthSeconds); seconds > 0 {
 		v.Duration = time.Duration(seconds) * time.Second
@@ -104,21 +146,129 @@ func (v *Video) extractDataFromPlayerResponse(prData playerResponseData) error {
 		v.PublishDate, _ = time.Parse(dateFormat, str)
 	}
 
+	v.CaptionTracks = nil
+	for _, track := range prData.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
+		v.CaptionTracks = append(v.CaptionTracks, track.CaptionTrack())
+	}
+
+	v.DefaultCaptionLanguage = defaultCaptionLanguage(prData)
+
+	v.Storyboards = parseStoryboardSpec(prData.Storyboards.PlayerStoryboardSpecRenderer.Spec)
+
+	v.ExpiresAt = time.Time{}
+	if seconds, _ := strconv.Atoi(prData.StreamingData.ExpiresInSeconds); seconds > 0 {
+		v.ExpiresAt = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+
+	v.HLSManifestURL = prData.StreamingData.HlsManifestURL
+	v.DASHManifestURL = prData.StreamingData.DashManifestURL
+
 	// Assign Streams
 	v.Formats = append(prData.StreamingData.Formats, prData.StreamingData.AdaptiveFormats...)
 	if len(v.Formats) == 0 {
-		return errors.New("no formats found in the server's answer")
+		switch {
+		case v.HLSManifestURL != "" || v.DASHManifestURL != "":
+			// e.g. live streams, the formats are only listed in the manifests
+			return fmt.Errorf("%w, only the HLS/DASH manifest is available", ErrNoFormats)
+		case prData.StreamingData.ExpiresInSeconds == "":
+			return fmt.Errorf("%w, the streamingData is missing", ErrNoFormats)
+		default:
+			return ErrNoFormats
+		}
+	}
+
+	v.Projection = ProjectionRectangular
+	for _, format := range v.Formats {
+		if format.Height > 0 {
+			v.Projection = parseProjection(format.ProjectionType)
+			break
+		}
 	}
 
 	// Sort formats by bitrate
 	sort.SliceStable(v.Formats, v.SortBitrateDesc)
 
-	v.HLSManifestURL = prData.StreamingData.HlsManifestURL
-	v.DASHManifestURL = prData.StreamingData.DashManifestURL
+	v.Formats.rankQuality()
 
 	return nil
 }
 
+// defaultCaptionLanguage returns the language of the caption track which is turned on by default
+func defaultCaptionLanguage(prData playerResponseData) string {
+	renderer := prData.Captions.PlayerCaptionsTracklistRenderer
+	if renderer.DefaultAudioTrackIndex < 0 || renderer.DefaultAudioTrackIndex >= len(renderer.AudioTracks) {
+		return ""
+	}
+
+	audioTrack := renderer.AudioTracks[renderer.DefaultAudioTrackIndex]
+	if !strings.HasPrefix(audioTrack.CaptionsInitialState, "CAPTIONS_INITIAL_STATE_ON") {
+		return ""
+	}
+
+	index := audioTrack.DefaultCaptionTrackIndex
+	if index == nil || *index < 0 || *index >= len(renderer.CaptionTracks) {
+		return ""
+	}
+	return renderer.CaptionTracks[*index].LanguageCode
+}
+
+// WatchURL returns the canonical watch URL of the video, including the requested start time
+func (v *Video) WatchURL() string {
+	url := "https://www.youtube.com/watch?v=" + v.ID
+	if seconds := int(v.RequestedStart.Seconds()); seconds > 0 {
+		url += "&t=" + strconv.Itoa(seconds) + "s"
+	}
+	return url
+}
+
+// ShareURL returns the short youtu.be URL of the video, including the requested start time
+func (v *Video) ShareURL() string {
+	url := "https://youtu.be/" + v.ID
+	if seconds := int(v.RequestedStart.Seconds()); seconds > 0 {
+		url += "?t=" + strconv.Itoa(seconds)
+	}
+	return url
+}
+
+// FormatsByHeight returns the formats having exactly the given height,
+// regardless of their quality label, FPS or codec
+func (v *Video) FormatsByHeight(h int) FormatList {
+	return v.Formats.Height(h)
+}
+
+// EstimatedDownloadSize returns the total number of bytes to download the given formats.
+// Either format may be nil, e.g. to estimate a single muxed format.
+// The exact ContentLength is used when available, otherwise the size is estimated
+// from the average bitrate (or bitrate) and the duration.
+func (v *Video) EstimatedDownloadSize(videoFormat, audioFormat *Format) int64 {
+	var size int64
+	for _, format := range []*Format{videoFormat, audioFormat} {
+		if format != nil {
+			size += v.estimatedFormatSize(format)
+		}
+	}
+	return size
+}
+
+func (v *Video) estimatedFormatSize(format *Format) int64 {
+	if format.ContentLength > 0 {
+		return format.ContentLength
+	}
+
+	bitrate := format.AverageBitrate
+	if bitrate == 0 {
+		// Some formats don't have the average bitrate
+		bitrate = format.Bitrate
+	}
+
+	duration := v.Duration
+	if ms, _ := strconv.ParseInt(format.ApproxDurationMs, 10, 64); ms > 0 {
+		duration = time.Duration(ms) * time.Millisecond
+	}
+
+	return int64(float64(bitrate) * duration.Seconds() / 8)
+}
+
 func (v *Video) SortBitrateDesc(i int, j int) bool {
 	return v.Formats[i].Bitrate > v.Formats[j].Bitrate
 }
@@ -1,12 +1,15 @@
 package youtube
 
 import (
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var videoRegexpList = []*regexp.Regexp{
-	regexp.MustCompile(`(?:v|embed|shorts|watch\?v)(?:=|/)([^"&?/=%]{11})`),
+	regexp.MustCompile(`(?:v|embed|shorts|podcast|watch\?v)(?:=|/)([^"&?/=%]{11})`),
 	regexp.MustCompile(`(?:=|/)([^"&?/=%]{11})`),
 	regexp.MustCompile(`([^"&?/=%]{11})`),
 }
@@ -31,3 +34,28 @@ func ExtractVideoID(videoID string) (string, error) {
 
 	return videoID, nil
 }
+
+// extractStartTime returns the start time given by the t or start parameter of a video URL,
+// e.g. t=90, t=90s or t=1m30s
+func extractStartTime(videoURL string) time.Duration {
+	uri, err := url.Parse(videoURL)
+	if err != nil {
+		return 0
+	}
+
+	query := uri.Query()
+	value := query.Get("t")
+	if value == "" {
+		value = query.Get("start")
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
@@ -2,7 +2,9 @@ package youtube
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -133,3 +135,215 @@ func TestDownload_SensitiveContent(t *testing.T) {
 	_, err := testClient.GetVideo("MS91knuzoOA")
 	require.EqualError(t, err, "can't bypass age restriction: embedding of this video has been disabled")
 }
+
+func TestVideo_EstimatedDownloadSize(t *testing.T) {
+	video := &Video{Duration: 10 * time.Second}
+
+	videoFormat := &Format{ContentLength: 1000, Bitrate: 8000}
+	audioFormat := &Format{AverageBitrate: 800, Bitrate: 1600}
+	assert.EqualValues(t, 2000, video.EstimatedDownloadSize(videoFormat, audioFormat))
+	assert.EqualValues(t, 1000, video.EstimatedDownloadSize(videoFormat, nil))
+	assert.EqualValues(t, 1000, video.EstimatedDownloadSize(nil, audioFormat))
+
+	// the duration of the format takes precedence
+	audioFormat.ApproxDurationMs = "5000"
+	assert.EqualValues(t, 500, video.EstimatedDownloadSize(nil, audioFormat))
+	assert.EqualValues(t, 0, video.EstimatedDownloadSize(nil, nil))
+}
+
+func TestVideo_WatchURL(t *testing.T) {
+	video := &Video{ID: "rFejpH_tAHM"}
+	assert.Equal(t, "https://www.youtube.com/watch?v=rFejpH_tAHM", video.WatchURL())
+	assert.Equal(t, "https://youtu.be/rFejpH_tAHM", video.ShareURL())
+
+	video.RequestedStart = 90 * time.Second
+	assert.Equal(t, "https://www.youtube.com/watch?v=rFejpH_tAHM&t=90s", video.WatchURL())
+	assert.Equal(t, "https://youtu.be/rFejpH_tAHM?t=90", video.ShareURL())
+}
+
+func TestExtractStartTime(t *testing.T) {
+	tests := []struct {
+		url  string
+		want time.Duration
+	}{
+		{"https://www.youtube.com/watch?v=rFejpH_tAHM", 0},
+		{"https://www.youtube.com/watch?v=rFejpH_tAHM&t=90", 90 * time.Second},
+		{"https://youtu.be/rFejpH_tAHM?t=90s", 90 * time.Second},
+		{"https://www.youtube.com/watch?v=rFejpH_tAHM&t=1m30s", 90 * time.Second},
+		{"https://www.youtube.com/embed/rFejpH_tAHM?start=30", 30 * time.Second},
+		{"https://www.youtube.com/watch?v=rFejpH_tAHM&t=invalid", 0},
+		{"rFejpH_tAHM", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractStartTime(tt.url))
+		})
+	}
+}
+
+func TestVideo_parseVideoInfo_NoFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{
+			name: "missing streamingData",
+			body: `{"playabilityStatus": {"status": "OK"}}`,
+			err:  "no formats found in the server's answer, the streamingData is missing",
+		},
+		{
+			name: "empty streamingData",
+			body: `{"playabilityStatus": {"status": "OK"}, "streamingData": {"expiresInSeconds": "21540", "formats": [], "adaptiveFormats": []}}`,
+			err:  "no formats found in the server's answer",
+		},
+		{
+			name: "manifest only",
+			body: `{"playabilityStatus": {"status": "OK"}, "streamingData": {"expiresInSeconds": "21540", "hlsManifestUrl": "https://manifest.googlevideo.com/hls"}}`,
+			err:  "no formats found in the server's answer, only the HLS/DASH manifest is available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Video{}
+			err := v.parseVideoInfo([]byte(tt.body))
+			assert.ErrorIs(t, err, ErrNoFormats)
+			assert.EqualError(t, err, tt.err)
+			assert.Empty(t, v.Formats)
+		})
+	}
+}
+
+func TestVideo_parseVideoInfo_Loudness(t *testing.T) {
+	v := &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]},
+		"playerConfig": {"audioConfig": {"loudnessDb": -3.47, "perceptualLoudnessDb": -17.47, "enablePerFormatLoudness": true}}
+	}`)))
+	assert.Equal(t, -3.47, v.LoudnessDb)
+	assert.Equal(t, -17.47, v.PerceptualLoudnessDb)
+}
+
+func TestVideo_parseVideoInfo_BotCheck(t *testing.T) {
+	v := &Video{}
+	err := v.parseVideoInfo([]byte(`{"playabilityStatus": {"status": "LOGIN_REQUIRED", "reason": "Sign in to confirm you’re not a bot"}}`))
+
+	var botErr *ErrBotCheck
+	require.ErrorAs(t, err, &botErr)
+	assert.Equal(t, "Sign in to confirm you’re not a bot", botErr.Reason)
+}
+
+func TestVideo_parseVideoInfo_ExpiresAt(t *testing.T) {
+	v := &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"expiresInSeconds": "21540", "formats": [{"itag": 18}]}
+	}`)))
+	assert.WithinDuration(t, time.Now().Add(21540*time.Second), v.ExpiresAt, time.Minute)
+
+	v = &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]}
+	}`)))
+	assert.True(t, v.ExpiresAt.IsZero())
+}
+
+func TestVideo_parseVideoInfo_Projection(t *testing.T) {
+	tests := []struct {
+		projectionType string
+		projection     Projection
+		spherical      bool
+	}{
+		{"", ProjectionRectangular, false},
+		{"RECTANGULAR", ProjectionRectangular, false},
+		{"EQUIRECTANGULAR", Projection360, true},
+		{"EQUIRECTANGULAR_THREED_TOP_BOTTOM", Projection360Stereo, true},
+		{"MESH", ProjectionMesh, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.projectionType, func(t *testing.T) {
+			v := &Video{}
+			require.NoError(t, v.parseVideoInfo([]byte(`{
+				"playabilityStatus": {"status": "OK"},
+				"streamingData": {"adaptiveFormats": [
+					{"itag": 140, "audioChannels": 2},
+					{"itag": 313, "height": 2160, "projectionType": "`+tt.projectionType+`"}
+				]}
+			}`)))
+			assert.Equal(t, tt.projection, v.Projection)
+			assert.Equal(t, tt.spherical, v.IsSpherical())
+		})
+	}
+}
+
+func TestVideo_parseVideoInfo_PosterThumbnail(t *testing.T) {
+	v := &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]},
+		"videoDetails": {"thumbnail": {"thumbnails": [
+			{"url": "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault.jpg?sqp=-oaymwE", "width": 168, "height": 94},
+			{"url": "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault.jpg?sqp=-oaymwF", "width": 336, "height": 188}
+		]}},
+		"microformat": {"playerMicroformatRenderer": {"thumbnail": {"thumbnails": [
+			{"url": "https://i.ytimg.com/vi/rFejpH_tAHM/maxresdefault.jpg", "width": 1280, "height": 720}
+		]}}}
+	}`)))
+	assert.Equal(t, Thumbnail{URL: "https://i.ytimg.com/vi/rFejpH_tAHM/maxresdefault.jpg", Width: 1280, Height: 720}, v.PosterThumbnail)
+	assert.Len(t, v.Thumbnails, 2)
+
+	// without microformat the largest thumbnail is used
+	v = &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]},
+		"videoDetails": {"thumbnail": {"thumbnails": [
+			{"url": "small", "width": 168, "height": 94},
+			{"url": "large", "width": 336, "height": 188}
+		]}}
+	}`)))
+	assert.Equal(t, "large", v.PosterThumbnail.URL)
+}
+
+func TestVideo_parseVideoInfo_DefaultCaptionLanguage(t *testing.T) {
+	parse := func(state string) string {
+		v := &Video{}
+		require.NoError(t, v.parseVideoInfo([]byte(`{
+			"playabilityStatus": {"status": "OK"},
+			"streamingData": {"formats": [{"itag": 18}]},
+			"captions": {"playerCaptionsTracklistRenderer": {
+				"captionTracks": [
+					{"baseUrl": "https://www.youtube.com/api/timedtext?lang=en", "languageCode": "en", "vssId": ".en"},
+					{"baseUrl": "https://www.youtube.com/api/timedtext?lang=de", "languageCode": "de", "vssId": ".de"}
+				],
+				"audioTracks": [{"captionTrackIndices": [0, 1], "defaultCaptionTrackIndex": 1, "captionsInitialState": "`+state+`"}],
+				"defaultAudioTrackIndex": 0
+			}}
+		}`)))
+		return v.DefaultCaptionLanguage
+	}
+
+	assert.Equal(t, "de", parse("CAPTIONS_INITIAL_STATE_ON_REQUIRED"))
+	assert.Equal(t, "", parse("CAPTIONS_INITIAL_STATE_OFF_RECOMMENDED"))
+	assert.Equal(t, "", parse(""))
+}
+
+func TestVideo_parseVideoInfo_Offlineable(t *testing.T) {
+	v := &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK", "offlineability": {"offlineabilityRenderer": {"offlineable": true}}},
+		"streamingData": {"formats": [{"itag": 18}]}
+	}`)))
+	assert.True(t, v.Offlineable)
+
+	// without the offlineability renderer the video isn't offlineable
+	v = &Video{}
+	require.NoError(t, v.parseVideoInfo([]byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18}]}
+	}`)))
+	assert.False(t, v.Offlineable)
+}
@@ -0,0 +1,442 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	sjson "github.com/bitly/go-simplejson"
+)
+
+// Link is a link in the description of a video.
+// Timestamps into the video itself have an empty URL.
+type Link struct {
+	Text  string
+	URL   string        // target of the link, redirects by YouTube are resolved
+	Start time.Duration // start time of timestamps and links to other videos
+}
+
+// KeyMoment is a moment of a video highlighted by YouTube
+type KeyMoment struct {
+	Title      string
+	Start      time.Duration
+	Thumbnails Thumbnails
+}
+
+// ProductTag is a product tagged in a video for shopping
+type ProductTag struct {
+	Title      string
+	Price      string // formatted price including the currency, e.g. "$19.99"
+	Merchant   string
+	URL        string
+	Thumbnails Thumbnails
+}
+
+// Chapter is a chapter of a video, which lasts until the start of the next one
+type Chapter struct {
+	Title      string
+	Start      time.Duration
+	Thumbnails Thumbnails
+}
+
+// DerivedClip is a short derived from a video, as listed in the shorts shelf of the watch page
+type DerivedClip struct {
+	ID         string
+	Title      string
+	Thumbnails Thumbnails
+}
+
+// structs for watch next extraction
+
+// Contents: contents.twoColumnWatchNextResults.results.results.contents
+// Description: [].videoSecondaryInfoRenderer.description.runs
+// Panels: engagementPanels[].engagementPanelSectionListRenderer
+// Clips: reelShelfRenderer anywhere in contents.twoColumnWatchNextResults
+// Comments: [].itemSectionRenderer with the sectionIdentifier comment-item-section
+func (v *Video) parseWatchNext(body []byte) (err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	v.CommentsDisabled = false
+	contents := j.GetPath("contents", "twoColumnWatchNextResults", "results", "results", "contents")
+	for i := range contents.MustArray() {
+		if renderer, ok := contents.GetIndex(i).CheckGet("videoSecondaryInfoRenderer"); ok {
+			if err = v.parseSecondaryInfo(renderer); err != nil {
+				return err
+			}
+		}
+
+		if section, ok := contents.GetIndex(i).CheckGet("itemSectionRenderer"); ok && section.Get("sectionIdentifier").MustString() == "comment-item-section" {
+			// enabled comments are loaded by a continuation, otherwise there is only a message
+			items := section.Get("contents").Interface()
+			v.CommentsDisabled = len(findRenderers(items, "continuationItemRenderer")) == 0 &&
+				len(findRenderers(items, "messageRenderer")) > 0
+		}
+	}
+
+	v.DerivedClips = nil
+	for _, shelf := range findRenderers(j.GetPath("contents", "twoColumnWatchNextResults").Interface(), "reelShelfRenderer") {
+		if err = v.parseReelShelf(shelf); err != nil {
+			return err
+		}
+	}
+
+	v.KeyMoments = nil
+	v.ProductTags = nil
+	v.Chapters = nil
+	v.chaptersContinuation = ""
+	panels := j.Get("engagementPanels")
+	for i := range panels.MustArray() {
+		if renderer, ok := panels.GetIndex(i).CheckGet("engagementPanelSectionListRenderer"); ok {
+			if err = v.parseEngagementPanel(renderer); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseEngagementPanel extracts the key moments and product tags of a panel.
+// The renderers are nested differently depending on the panel, so they are searched recursively.
+func (v *Video) parseEngagementPanel(renderer *sjson.Json) error {
+	panelID := renderer.Get("panelIdentifier").MustString(renderer.Get("targetId").MustString())
+
+	// the description chapters are preferred, they are listed before the generated ones
+	if strings.Contains(panelID, "macro-markers") && strings.Contains(panelID, "chapters") && v.Chapters == nil {
+		continuation, err := v.appendChapters(renderer.GetPath("content", "macroMarkersListRenderer", "contents").Interface())
+		if err != nil {
+			return err
+		}
+		v.chaptersContinuation = continuation
+	}
+
+	if strings.Contains(panelID, "key-moments") {
+		for _, data := range findRenderers(renderer.Interface(), "macroMarkersListItemRenderer") {
+			var marker macroMarker
+			if err := remarshal(data, &marker); err != nil {
+				return err
+			}
+			v.KeyMoments = append(v.KeyMoments, KeyMoment{
+				Title:      marker.Title.String(),
+				Start:      time.Duration(marker.OnTap.WatchEndpoint.StartTimeSeconds) * time.Second,
+				Thumbnails: marker.Thumbnail.Thumbnails,
+			})
+		}
+	}
+
+	for _, data := range findRenderers(renderer.Interface(), "productListItemRenderer") {
+		var product productListItem
+		if err := remarshal(data, &product); err != nil {
+			return err
+		}
+		productURL, _ := ResolveRedirectURL(product.OnClickCommand.URLEndpoint.URL)
+		v.ProductTags = append(v.ProductTags, ProductTag{
+			Title:      product.Title.String(),
+			Price:      product.Price,
+			Merchant:   product.MerchantName,
+			URL:        productURL,
+			Thumbnails: product.Thumbnail.Thumbnails,
+		})
+	}
+
+	return nil
+}
+
+// parseReelShelf extracts the clips of a shelf
+func (v *Video) parseReelShelf(shelf interface{}) error {
+	clips, err := parseReelItems(shelf)
+	if err != nil {
+		return err
+	}
+	v.DerivedClips = append(v.DerivedClips, clips...)
+	return nil
+}
+
+// parseReelItems extracts the shorts below data, which are either reel items or shorts lockups
+func parseReelItems(data interface{}) ([]DerivedClip, error) {
+	var clips []DerivedClip
+	for _, r := range findAnyRenderers(data, "reelItemRenderer", "shortsLockupViewModel") {
+		if r.Key == "reelItemRenderer" {
+			var item reelItem
+			if err := remarshal(r.Data, &item); err != nil {
+				return nil, err
+			}
+			clips = append(clips, DerivedClip{
+				ID:         item.VideoID,
+				Title:      item.Headline.String(),
+				Thumbnails: item.Thumbnail.Thumbnails,
+			})
+			continue
+		}
+
+		var lockup shortsLockup
+		if err := remarshal(r.Data, &lockup); err != nil {
+			return nil, err
+		}
+		clips = append(clips, DerivedClip{
+			ID:         lockup.OnTap.InnertubeCommand.ReelWatchEndpoint.VideoID,
+			Title:      lockup.OverlayMetadata.PrimaryText.Content,
+			Thumbnails: lockup.Thumbnail.Sources,
+		})
+	}
+
+	return clips, nil
+}
+
+type reelItem struct {
+	VideoID   string     `json:"videoId"`
+	Headline  simpleText `json:"headline"`
+	Thumbnail struct {
+		Thumbnails Thumbnails `json:"thumbnails"`
+	} `json:"thumbnail"`
+}
+
+type shortsLockup struct {
+	OnTap struct {
+		InnertubeCommand struct {
+			ReelWatchEndpoint struct {
+				VideoID string `json:"videoId"`
+			} `json:"reelWatchEndpoint"`
+		} `json:"innertubeCommand"`
+	} `json:"onTap"`
+	OverlayMetadata struct {
+		PrimaryText struct {
+			Content string `json:"content"`
+		} `json:"primaryText"`
+	} `json:"overlayMetadata"`
+	Thumbnail struct {
+		Sources Thumbnails `json:"sources"`
+	} `json:"thumbnail"`
+}
+
+// appendChapters adds the chapters of macro marker items and returns the continuation token, if any
+func (v *Video) appendChapters(items interface{}) (string, error) {
+	for _, data := range findRenderers(items, "macroMarkersListItemRenderer") {
+		var marker macroMarker
+		if err := remarshal(data, &marker); err != nil {
+			return "", err
+		}
+		v.Chapters = append(v.Chapters, Chapter{
+			Title:      marker.Title.String(),
+			Start:      time.Duration(marker.OnTap.WatchEndpoint.StartTimeSeconds) * time.Second,
+			Thumbnails: marker.Thumbnail.Thumbnails,
+		})
+	}
+
+	for _, data := range findRenderers(items, "continuationItemRenderer") {
+		var item continuationItem
+		if err := remarshal(data, &item); err != nil {
+			return "", err
+		}
+		if token := item.ContinuationEndpoint.ContinuationCommand.Token; token != "" {
+			return token, nil
+		}
+	}
+
+	return "", nil
+}
+
+// parseChaptersContinuation adds the chapters of a continuation response and returns the next continuation token.
+// Chapters: onResponseReceivedEndpoints[].appendContinuationItemsAction.continuationItems
+func (v *Video) parseChaptersContinuation(body []byte) (continuation string, err error) {
+	var j *sjson.Json
+	j, err = sjson.NewJson(body)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON parsing error: %v", r)
+		}
+	}()
+
+	return v.appendChapters(j.Get("onResponseReceivedEndpoints").Interface())
+}
+
+type continuationItem struct {
+	ContinuationEndpoint struct {
+		ContinuationCommand struct {
+			Token string `json:"token"`
+		} `json:"continuationCommand"`
+	} `json:"continuationEndpoint"`
+}
+
+type macroMarker struct {
+	Title simpleText `json:"title"`
+	OnTap struct {
+		WatchEndpoint struct {
+			StartTimeSeconds int `json:"startTimeSeconds"`
+		} `json:"watchEndpoint"`
+	} `json:"onTap"`
+	Thumbnail struct {
+		Thumbnails Thumbnails `json:"thumbnails"`
+	} `json:"thumbnail"`
+}
+
+type productListItem struct {
+	Title          simpleText `json:"title"`
+	Price          string     `json:"price"`
+	MerchantName   string     `json:"merchantName"`
+	OnClickCommand struct {
+		URLEndpoint struct {
+			URL string `json:"url"`
+		} `json:"urlEndpoint"`
+	} `json:"onClickCommand"`
+	Thumbnail struct {
+		Thumbnails Thumbnails `json:"thumbnails"`
+	} `json:"thumbnail"`
+}
+
+// findRenderers returns the values of all keys with the given name in the JSON data, keeping the order of arrays
+func findRenderers(data interface{}, key string) (result []interface{}) {
+	for _, r := range findAnyRenderers(data, key) {
+		result = append(result, r.Data)
+	}
+	return result
+}
+
+// renderer is a value found by findAnyRenderers and the key it was found by
+type renderer struct {
+	Key  string
+	Data interface{}
+}
+
+// findAnyRenderers returns the values of all keys with one of the given names in the JSON data,
+// in the order of the arrays, so renderers of different kinds stay in the order they are displayed
+func findAnyRenderers(data interface{}, names ...string) (result []renderer) {
+	switch data := data.(type) {
+	case map[string]interface{}:
+		for _, name := range names {
+			if value, ok := data[name]; ok {
+				return append(result, renderer{Key: name, Data: value})
+			}
+		}
+		// sort the keys to keep the order stable
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result = append(result, findAnyRenderers(data[k], names...)...)
+		}
+	case []interface{}:
+		for _, value := range data {
+			result = append(result, findAnyRenderers(value, names...)...)
+		}
+	}
+	return result
+}
+
+// remarshal converts generic JSON data into a typed struct
+func remarshal(data interface{}, v interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (v *Video) parseSecondaryInfo(renderer *sjson.Json) error {
+	data, err := renderer.GetPath("description", "runs").MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var runs []descriptionRun
+	if err = json.Unmarshal(data, &runs); err != nil {
+		return err
+	}
+
+	v.DescriptionLinks = nil
+	for _, run := range runs {
+		if link, ok := run.Link(v.ID); ok {
+			v.DescriptionLinks = append(v.DescriptionLinks, link)
+		}
+	}
+
+	return nil
+}
+
+type descriptionRun struct {
+	Text               string `json:"text"`
+	NavigationEndpoint *struct {
+		CommandMetadata struct {
+			WebCommandMetadata struct {
+				URL string `json:"url"`
+			} `json:"webCommandMetadata"`
+		} `json:"commandMetadata"`
+		URLEndpoint *struct {
+			URL string `json:"url"`
+		} `json:"urlEndpoint"`
+		WatchEndpoint *struct {
+			VideoID          string `json:"videoId"`
+			StartTimeSeconds int    `json:"startTimeSeconds"`
+		} `json:"watchEndpoint"`
+	} `json:"navigationEndpoint"`
+}
+
+// Link returns the link of the run, if it has one
+func (run descriptionRun) Link(videoID string) (Link, bool) {
+	endpoint := run.NavigationEndpoint
+	if endpoint == nil {
+		return Link{}, false
+	}
+
+	link := Link{Text: run.Text}
+
+	switch {
+	case endpoint.URLEndpoint != nil:
+		link.URL, _ = ResolveRedirectURL(endpoint.URLEndpoint.URL)
+	case endpoint.WatchEndpoint != nil:
+		link.Start = time.Duration(endpoint.WatchEndpoint.StartTimeSeconds) * time.Second
+		if endpoint.WatchEndpoint.VideoID != videoID {
+			link.URL = absoluteURL(endpoint.CommandMetadata.WebCommandMetadata.URL)
+		}
+	default:
+		link.URL = absoluteURL(endpoint.CommandMetadata.WebCommandMetadata.URL)
+	}
+
+	return link, true
+}
+
+// ResolveRedirectURL returns the target of a youtube.com/redirect?q= link as used in descriptions
+// and comments, without following it. Other URLs are returned as they are.
+// If the URL can't be parsed it is returned unchanged together with the error.
+func ResolveRedirectURL(link string) (string, error) {
+	uri, err := url.Parse(link)
+	if err != nil {
+		return link, err
+	}
+
+	host := uri.Hostname()
+	if (host != "youtube.com" && !strings.HasSuffix(host, ".youtube.com")) || uri.Path != "/redirect" {
+		return link, nil
+	}
+
+	if target := uri.Query().Get("q"); target != "" {
+		return target, nil
+	}
+	return link, nil
+}
+
+func absoluteURL(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return "https://www.youtube.com" + path
+	}
+	return path
+}
@@ -0,0 +1,190 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideo_parseWatchNext(t *testing.T) {
+	body := []byte(`{"contents": {"twoColumnWatchNextResults": {"results": {"results": {"contents": [
+		{"videoPrimaryInfoRenderer": {}},
+		{"videoSecondaryInfoRenderer": {"description": {"runs": [
+			{"text": "Intro at "},
+			{"text": "1:05", "navigationEndpoint": {"commandMetadata": {"webCommandMetadata": {"url": "/watch?v=rFejpH_tAHM&t=65s"}}, "watchEndpoint": {"videoId": "rFejpH_tAHM", "startTimeSeconds": 65}}},
+			{"text": "\nSlides: "},
+			{"text": "https://talks.golang.org/...", "navigationEndpoint": {"commandMetadata": {"webCommandMetadata": {"url": "https://www.youtube.com/redirect?event=video_description&q=https%3A%2F%2Ftalks.golang.org%2F2015%2Fsimplicity-is-complicated.slide"}}, "urlEndpoint": {"url": "https://www.youtube.com/redirect?event=video_description&q=https%3A%2F%2Ftalks.golang.org%2F2015%2Fsimplicity-is-complicated.slide"}}},
+			{"text": "\nNext talk: "},
+			{"text": "dotGo 2016", "navigationEndpoint": {"commandMetadata": {"webCommandMetadata": {"url": "/watch?v=cQ7STILAS0M"}}, "watchEndpoint": {"videoId": "cQ7STILAS0M"}}},
+			{"text": "#golang", "navigationEndpoint": {"commandMetadata": {"webCommandMetadata": {"url": "/hashtag/golang"}}, "browseEndpoint": {"browseId": "FEhashtag"}}}
+		]}}}
+	]}}}}}`)
+
+	v := &Video{ID: "rFejpH_tAHM"}
+	require.NoError(t, v.parseWatchNext(body))
+
+	assert.Equal(t, []Link{
+		{Text: "1:05", Start: 65 * time.Second},
+		{Text: "https://talks.golang.org/...", URL: "https://talks.golang.org/2015/simplicity-is-complicated.slide"},
+		{Text: "dotGo 2016", URL: "https://www.youtube.com/watch?v=cQ7STILAS0M"},
+		{Text: "#golang", URL: "https://www.youtube.com/hashtag/golang"},
+	}, v.DescriptionLinks)
+
+	v = &Video{ID: "rFejpH_tAHM"}
+	require.NoError(t, v.parseWatchNext([]byte(`{}`)))
+	assert.Empty(t, v.DescriptionLinks)
+}
+
+func TestVideo_parseWatchNext_EngagementPanels(t *testing.T) {
+	body := []byte(`{"engagementPanels": [
+		{"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-macro-markers-description-chapters", "content": {"macroMarkersListRenderer": {"contents": [
+			{"macroMarkersListItemRenderer": {"title": {"simpleText": "Chapter"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 0}}}}
+		]}}}},
+		{"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-macro-markers-key-moments", "content": {"macroMarkersListRenderer": {"contents": [
+			{"macroMarkersListItemRenderer": {"title": {"simpleText": "The drop"}, "onTap": {"watchEndpoint": {"videoId": "rFejpH_tAHM", "startTimeSeconds": 95}}, "thumbnail": {"thumbnails": [{"url": "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault_95000.jpg", "width": 168, "height": 94}]}}},
+			{"macroMarkersListItemRenderer": {"title": {"runs": [{"text": "Encore"}]}, "onTap": {"watchEndpoint": {"startTimeSeconds": 240}}}}
+		]}}}},
+		{"engagementPanelSectionListRenderer": {"panelIdentifier": "shopping_panel_for_entry_point_5", "content": {"sectionListRenderer": {"contents": [{"itemSectionRenderer": {"contents": [
+			{"productListItemRenderer": {"title": {"simpleText": "Gopher plush"}, "price": "$19.99", "merchantName": "Go Store", "onClickCommand": {"urlEndpoint": {"url": "https://www.youtube.com/redirect?q=https%3A%2F%2Fstore.golang.org%2Fplush"}}}}
+		]}}]}}}}
+	]}`)
+
+	v := &Video{ID: "rFejpH_tAHM"}
+	require.NoError(t, v.parseWatchNext(body))
+
+	assert.Equal(t, []KeyMoment{
+		{Title: "The drop", Start: 95 * time.Second, Thumbnails: Thumbnails{{URL: "https://i.ytimg.com/vi/rFejpH_tAHM/hqdefault_95000.jpg", Width: 168, Height: 94}}},
+		{Title: "Encore", Start: 240 * time.Second},
+	}, v.KeyMoments)
+	assert.Equal(t, []ProductTag{
+		{Title: "Gopher plush", Price: "$19.99", Merchant: "Go Store", URL: "https://store.golang.org/plush"},
+	}, v.ProductTags)
+
+	require.NoError(t, v.parseWatchNext([]byte(`{}`)))
+	assert.Empty(t, v.KeyMoments)
+	assert.Empty(t, v.ProductTags)
+}
+
+func TestResolveRedirectURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.youtube.com/redirect?event=video_description&q=https%3A%2F%2Fgo.dev%2Fdoc%3Fa%3D1%26b%3D2", "https://go.dev/doc?a=1&b=2"},
+		{"https://youtube.com/redirect?q=https://go.dev", "https://go.dev"},
+		{"https://www.youtube.com/redirect?event=video_description", "https://www.youtube.com/redirect?event=video_description"},
+		{"https://www.youtube.com/watch?v=rFejpH_tAHM", "https://www.youtube.com/watch?v=rFejpH_tAHM"},
+		{"https://notyoutube.com/redirect?q=https://go.dev", "https://notyoutube.com/redirect?q=https://go.dev"},
+		{"https://go.dev", "https://go.dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			target, err := ResolveRedirectURL(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, target)
+		})
+	}
+
+	target, err := ResolveRedirectURL("invalid\nurl")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid\nurl", target)
+}
+
+func TestVideo_parseWatchNext_DerivedClips(t *testing.T) {
+	body := []byte(`{"contents": {"twoColumnWatchNextResults": {"secondaryResults": {"secondaryResults": {"results": [
+		{"compactVideoRenderer": {"videoId": "cQ7STILAS0M"}},
+		{"reelShelfRenderer": {"title": {"runs": [{"text": "Shorts remixing this video"}]}, "items": [
+			{"reelItemRenderer": {"videoId": "aaaaaaaaaaa", "headline": {"simpleText": "Best part"}, "thumbnail": {"thumbnails": [{"url": "https://i.ytimg.com/vi/aaaaaaaaaaa/frame0.jpg", "width": 405, "height": 720}]}}},
+			{"shortsLockupViewModel": {"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "bbbbbbbbbbb"}}}, "overlayMetadata": {"primaryText": {"content": "Remix"}}}}
+		]}}
+	]}}}}}`)
+
+	v := &Video{ID: "rFejpH_tAHM"}
+	require.NoError(t, v.parseWatchNext(body))
+
+	assert.Equal(t, []DerivedClip{
+		{ID: "aaaaaaaaaaa", Title: "Best part", Thumbnails: Thumbnails{{URL: "https://i.ytimg.com/vi/aaaaaaaaaaa/frame0.jpg", Width: 405, Height: 720}}},
+		{ID: "bbbbbbbbbbb", Title: "Remix"},
+	}, v.DerivedClips)
+
+	require.NoError(t, v.parseWatchNext([]byte(`{}`)))
+	assert.Empty(t, v.DerivedClips)
+}
+
+// roundTripFunc answers the requests of a client without network
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestClient_GetVideoDetailsContext_Chapters(t *testing.T) {
+	responses := map[string]string{
+		"": `{"engagementPanels": [
+			{"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-macro-markers-description-chapters", "content": {"macroMarkersListRenderer": {"contents": [
+				{"macroMarkersListItemRenderer": {"title": {"simpleText": "Intro"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 0}}}},
+				{"macroMarkersListItemRenderer": {"title": {"simpleText": "Day 1"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 600}}}},
+				{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page2"}}}}
+			]}}}},
+			{"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-macro-markers-auto-chapters", "content": {"macroMarkersListRenderer": {"contents": [
+				{"macroMarkersListItemRenderer": {"title": {"simpleText": "Generated"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 0}}}}
+			]}}}}
+		]}`,
+		"page2": `{"onResponseReceivedEndpoints": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"macroMarkersListItemRenderer": {"title": {"simpleText": "Day 2"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 86400}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "page3"}}}}
+		]}}]}`,
+		"page3": `{"onResponseReceivedEndpoints": [{"appendContinuationItemsAction": {"continuationItems": [
+			{"macroMarkersListItemRenderer": {"title": {"simpleText": "Outro"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 172800}}}}
+		]}}]}`,
+	}
+
+	client := &Client{HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var request innertubeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(responses[request.Continuation])),
+		}
+	})}}
+
+	v := &Video{ID: "rFejpH_tAHM"}
+	require.NoError(t, client.GetVideoDetailsContext(context.Background(), v))
+
+	assert.Equal(t, []Chapter{
+		{Title: "Intro"},
+		{Title: "Day 1", Start: 10 * time.Minute},
+		{Title: "Day 2", Start: 24 * time.Hour},
+		{Title: "Outro", Start: 48 * time.Hour},
+	}, v.Chapters)
+	assert.Empty(t, v.chaptersContinuation)
+}
+
+func TestVideo_parseWatchNext_CommentsDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		section  string
+		disabled bool
+	}{
+		{"enabled", `{"itemSectionRenderer": {"sectionIdentifier": "comment-item-section", "contents": [{"continuationItemRenderer": {"trigger": "CONTINUATION_TRIGGER_ON_ITEM_SHOWN"}}]}}`, false},
+		{"disabled", `{"itemSectionRenderer": {"sectionIdentifier": "comment-item-section", "contents": [{"messageRenderer": {"text": {"runs": [{"text": "Comments are turned off. "}]}}}]}}`, true},
+		{"unknown", `{"itemSectionRenderer": {"sectionIdentifier": "other-section", "contents": [{"messageRenderer": {}}]}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Video{}
+			require.NoError(t, v.parseWatchNext([]byte(`{"contents": {"twoColumnWatchNextResults": {"results": {"results": {"contents": [`+tt.section+`]}}}}}`)))
+			assert.Equal(t, tt.disabled, v.CommentsDisabled)
+		})
+	}
+}